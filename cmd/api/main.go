@@ -1,10 +1,30 @@
 package main
 
-import "github.com/nutsp/golang-clean-architecture/internal/container"
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/nutsp/golang-clean-architecture/internal/app"
+	"github.com/nutsp/golang-clean-architecture/internal/container"
+)
 
 func main() {
+	printGraph := flag.Bool("print-graph", false, "print the dependency graph in DOT format and exit, e.g. --print-graph | dot -Tsvg > graph.svg")
+	flag.Parse()
+
 	cn := container.NewContainer()
+
+	if *printGraph {
+		if err := cn.PrintGraph(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(app.ExitFailure)
+		}
+		return
+	}
+
 	if err := cn.Run(); err != nil {
-		panic(err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(app.ExitCode(err))
 	}
 }
@@ -0,0 +1,55 @@
+// Command cachetool counts or purges the Redis keys of the application namespace.
+//
+//	go run cmd/cachetool/main.go -match 'users:*'          # count the cached users of every schema version
+//	go run cmd/cachetool/main.go -match 'users:v1:*' -purge # delete the users cached with schema v1
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/nutsp/golang-clean-architecture/config"
+	"github.com/nutsp/golang-clean-architecture/pkg/datasource"
+)
+
+func main() {
+	match := flag.String("match", "*", "glob pattern of the keys, relative to the namespace")
+	namespace := flag.String("namespace", "", "namespace to scan instead of the configured one")
+	purge := flag.Bool("purge", false, "delete the matching keys instead of only counting them")
+	flag.Parse()
+
+	cfg, err := config.NewLoadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	ns := *namespace
+	if ns == "" {
+		ns = datasource.KeyNamespace(cfg.App, cfg.Redis)
+	}
+
+	client, err := datasource.NewRedisClient(cfg.Redis, ns)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer client.Close()
+
+	pattern := strings.TrimSuffix(client.NamespacePattern(), "*") + *match
+
+	n, err := datasource.PurgeKeys(context.Background(), client, pattern, !*purge)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if *purge {
+		fmt.Printf("deleted %d keys matching %s\n", n, pattern)
+	} else {
+		fmt.Printf("found %d keys matching %s (dry run, use -purge to delete)\n", n, pattern)
+	}
+}
@@ -0,0 +1,51 @@
+// Command config checks or prints the configuration without starting the application,
+// e.g. in CI or before a deployment.
+//
+//	go run cmd/config/main.go check                            # config/config.yaml and its overlay
+//	APP_ENVIRONMENT=production go run cmd/config/main.go check # the production overlay
+//	go run cmd/config/main.go check -dir deploy -name app      # deploy/app.yaml
+//	go run cmd/config/main.go print                            # the resolved config, secrets redacted
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/nutsp/golang-clean-architecture/config"
+)
+
+const usage = "usage: config check|print [-dir config] [-name config]"
+
+func main() {
+	if len(os.Args) < 2 || (os.Args[1] != "check" && os.Args[1] != "print") {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	command := os.Args[1]
+
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	dir := flags.String("dir", "config", "directory of the configuration files")
+	name := flags.String("name", "config", "name of the base configuration file, without extension")
+	_ = flags.Parse(os.Args[2:])
+
+	cfg, err := config.Load(*dir, *name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if command == "print" {
+		// Secrets marshal as [REDACTED].
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(cfg); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Printf("OK: %s/%s.yaml is valid for environment %s\n", *dir, *name, cfg.App.Environment)
+}
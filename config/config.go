@@ -3,8 +3,10 @@ package config
 import (
 	"errors"
 	"fmt"
-	"log"
+	"path/filepath"
+	"reflect"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -20,127 +22,359 @@ type (
 		Authentication AuthenticationConfig
 		Observability  ObservabilityConfig
 		JWT            JWTConfig
+		RateLimit      RateLimitConfig `reload:"true"`
+		Idempotency    IdempotencyConfig
+		Health         HealthConfig
+		RequestLog     RequestLogConfig
+		Admin          AdminConfig
+		Metrics        MetricsConfig
+		Lifecycle      LifecycleConfig
+		Features       FeaturesConfig
 	}
 
 	// AppConfig holds the configuration related to the application settings.
 	AppConfig struct {
-		Name        string
+		Name        string `validate:"required"`
 		Version     string
-		Schema      string
+		Scheme      string `default:"http" validate:"oneof=http https"`
 		Host        string
-		Environment string
+		Environment string `default:"local" validate:"oneof=local development staging production"` // Also selects the overlay file config.<Environment>.yaml.
 	}
 
 	// ServerConfig holds the configuration for the server settings.
 	ServerConfig struct {
-		Port     string // The port on which the server will listen.
+		Port     string `default:"9090" validate:"required,numeric"` // The port on which the server will listen.
 		Debug    bool   // Indicates if debug mode is enabled.
 		TimeZone string // The time zone setting for the server.
+
+		ReadTimeout       time.Duration `default:"15s"`        // Time allowed to read a whole request, body included.
+		ReadHeaderTimeout time.Duration `default:"5s"`         // Time allowed to read the request headers.
+		WriteTimeout      time.Duration `default:"30s"`        // Time allowed to write the response, from the end of the request headers.
+		IdleTimeout       time.Duration `default:"2m"`         // How long a keep-alive connection waits for the next request.
+		BodyLimit         string        `default:"1M"`         // Largest request body accepted, e.g. 512K or 2M.
+		TrustedProxies    []string      `validate:"dive,cidr"` // CIDRs of the proxies whose X-Forwarded-For is trusted, the connection IP is used otherwise.
+		CORS              ServerCORS
+		Headers           ServerHeaders
+	}
+
+	// ServerCORS holds the cross-origin requests allowed, usually set per environment in its overlay.
+	ServerCORS struct {
+		AllowOrigins     []string      // Origins allowed, CORS is disabled when empty. "*" is refused in production.
+		AllowCredentials bool          // Lets browsers send cookies, requires explicit origins.
+		MaxAge           time.Duration // How long browsers reuse a preflight response.
+	}
+
+	// ServerHeaders holds the security headers sent with every response.
+	ServerHeaders struct {
+		HSTSMaxAge            time.Duration // Strict-Transport-Security max-age, sent on HTTPS requests only. Off when zero.
+		HSTSIncludeSubdomains bool
+		HSTSPreload           bool
+		ContentSecurityPolicy string
+		ReferrerPolicy        string `default:"no-referrer"`
+		FrameOptions          string `default:"DENY" validate:"oneof=DENY SAMEORIGIN"` // X-Frame-Options.
 	}
 
 	// DatabaseConfig holds the configuration for the database connection.
 	DatabaseConfig struct {
-		Host     string
-		Port     int
-		Name     string
-		User     string
-		Password string
+		Host     string `validate:"required"`
+		Port     int    `default:"3306" validate:"min=1,max=65535"`
+		Name     string `validate:"required"`
+		User     string `validate:"required"`
+		Password Secret
 	}
 
+	// HttpClient holds the default settings for outbound HTTP calls and the per-service overrides.
 	HttpClient struct {
+		Timeout        time.Duration          `default:"10s"` // Default timeout of a whole request, including reading the body.
+		Headers        map[string]Secret      // Headers sent with every request, they may carry credentials.
+		Retry          HttpRetry              // Default retry policy.
+		CircuitBreaker HttpCircuitBreaker     // Default per-host circuit breaker.
+		RateLimit      HttpRateLimit          // Default client-side rate limit.
+		Services       map[string]HttpService `validate:"dive"` // Per-service settings, keyed by service name.
+	}
+
+	// HttpService holds the settings for a single external service.
+	HttpService struct {
+		BaseURL        string              `validate:"omitempty,url"` // URL that relative request URLs are resolved against.
+		Timeout        time.Duration       // Overrides HttpClient.Timeout when set.
+		Headers        map[string]Secret   // Merged over HttpClient.Headers.
+		Retry          *HttpRetry          // Overrides HttpClient.Retry when set.
+		CircuitBreaker *HttpCircuitBreaker // Overrides HttpClient.CircuitBreaker when set.
+		RateLimit      *HttpRateLimit      // Overrides HttpClient.RateLimit when set.
+		Auth           HttpAuth            // Credentials sent with every request to the service.
+	}
+
+	// HttpAuth holds the credentials of an external service.
+	HttpAuth struct {
+		Type   string `validate:"omitempty,oneof=bearer apikey"` // "bearer", "apikey" or empty for none.
+		Token  Secret `validate:"required_with=Type"`
+		Header string // Header carrying the API key, X-API-Key when empty.
+	}
+
+	// HttpRetry holds the retry policy for idempotent requests.
+	HttpRetry struct {
+		MaxAttempts  int           // Attempts including the first one, 0 or 1 disables retries.
+		InitialDelay time.Duration // Base delay of the exponential backoff.
+		MaxDelay     time.Duration // Upper bound of a single backoff delay.
+		StatusCodes  []int         // Response statuses that are retried besides transport errors.
+	}
+
+	// HttpCircuitBreaker holds the settings of the per-host circuit breakers.
+	HttpCircuitBreaker struct {
+		Enable           bool
+		FailureThreshold int           `validate:"required_if=Enable true,gte=0"` // Consecutive failures that open the circuit.
+		OpenTimeout      time.Duration // How long the circuit stays open before a trial request is let through.
+	}
+
+	// HttpRateLimit holds the settings of the client-side token bucket.
+	HttpRateLimit struct {
+		RequestsPerSecond float64 // 0 disables the limiter.
+		Burst             int
 	}
 
 	Logger struct {
-		Mode     string
-		Encoding string
+		Mode     string   `default:"production" validate:"oneof=development production"`
+		Encoding string   `default:"json" validate:"oneof=json console"`
+		Level    string   `validate:"omitempty,oneof=debug info warn error" reload:"true"` // "debug", "info", "warn" or "error", debug when empty.
+		Outputs  []string `validate:"dive,oneof=stdout stderr file"`                       // "stdout", "stderr" or "file", stderr when empty.
+		File     LoggerFile
+		Sampling LoggerSampling
+	}
+
+	// LoggerFile holds the settings of the "file" output, rotated by size.
+	LoggerFile struct {
+		Path       string
+		MaxSize    int // Megabytes before the file is rotated, 100 when zero.
+		MaxBackups int // Rotated files kept, all when zero.
+		MaxAge     int // Days rotated files are kept, forever when zero.
+		Compress   bool
+	}
+
+	// LoggerSampling caps the lines logged per second with the same level and message.
+	LoggerSampling struct {
+		Enable     bool
+		Initial    int // Lines logged each second before sampling starts.
+		Thereafter int // Then one line out of Thereafter is logged.
 	}
 
+	// Redis holds the configuration of the Redis connection.
 	Redis struct {
-		Addr     string
-		Password string
-		DB       int
+		Mode             string   `validate:"omitempty,oneof=single sentinel cluster universal"` // "single" (default), "sentinel", "cluster" or "universal".
+		Addr             string   `validate:"required_without=Addrs"`                            // Address of the node in single mode.
+		Addrs            []string // Sentinel or cluster seed addresses; take precedence over Addr.
+		MasterName       string   `validate:"required_if=Mode sentinel"` // Name of the master monitored by Sentinel.
+		Username         string
+		Password         Secret
+		SentinelUsername string
+		SentinelPassword Secret
+		DB               int    // Ignored in cluster mode.
+		Namespace        string // Prefix of every key, "<App.Name>:<App.Environment>" when empty.
+
+		PoolSize     int           // Connections per node, go-redis default when zero.
+		MinIdleConns int           // Idle connections kept open per node.
+		DialTimeout  time.Duration // Also bounds the startup ping.
+		ReadTimeout  time.Duration
+		WriteTimeout time.Duration
+		PoolTimeout  time.Duration // How long to wait for a free connection.
+
+		TLS RedisTLS
+	}
+
+	// RedisTLS holds the TLS settings of the Redis connection.
+	RedisTLS struct {
+		Enable             bool
+		CAFile             string // PEM bundle used instead of the system roots when set.
+		CertFile           string // Client certificate for mutual TLS.
+		KeyFile            string
+		ServerName         string
+		InsecureSkipVerify bool
 	}
 
 	AuthenticationConfig struct {
-		Key string
+		Key Secret
 	}
 
 	// ObservabilityConfig holds the configuration for observability settings.
 	ObservabilityConfig struct {
-		Enable bool   // Indicates if observability is enabled.
-		Mode   string // Specifies the observability mode.
+		Enable      bool              // Indicates if observability is enabled.
+		Mode        string            `validate:"omitempty,oneof=otlp/http stdout memory"` // Specifies the observability mode: "otlp/http", "stdout" or "memory".
+		Endpoint    string            // OTLP collector host:port, the exporter default when empty.
+		Insecure    bool              // Sends OTLP over plain HTTP.
+		Headers     map[string]Secret // Sent with every OTLP export, e.g. an API key.
+		SampleRatio float64           `validate:"gte=0,lte=1"` // Fraction of new traces recorded, all of them when 0.
+	}
+
+	// HealthConfig holds the settings of the liveness and readiness probes.
+	HealthConfig struct {
+		Timeout       time.Duration   `default:"2s"` // Maximum duration of a single dependency check.
+		CacheTTL      time.Duration   `default:"1s"` // How long a readiness report is reused.
+		ShutdownDelay time.Duration   `default:"5s"` // How long readiness fails before the server stops accepting requests.
+		Mailer        HealthHTTPCheck // Optional check of the mailer API.
+	}
+
+	// HealthHTTPCheck holds the settings of an optional check of an external API.
+	HealthHTTPCheck struct {
+		Enable bool
+		Path   string `validate:"required_if=Enable true"` // Requested with GET, any status below 500 is healthy.
+	}
+
+	// LifecycleConfig holds the timeouts of the start and of the graceful shutdown.
+	LifecycleConfig struct {
+		StartTimeout time.Duration `default:"15s"` // Time allowed to every OnStart hook together, 15s when zero.
+		StopTimeout  time.Duration `default:"30s"` // Time allowed to every OnStop hook together, 30s when zero.
+		DrainTimeout time.Duration `default:"10s"` // Time allowed to in-flight requests once the server stops accepting new ones, 10s when zero.
+	}
+
+	// AdminConfig holds the access to the admin endpoints, such as the log level.
+	AdminConfig struct {
+		Token Secret // Bearer token of the admin endpoints, they are not registered when empty.
+	}
+
+	// MetricsConfig holds the access to the metrics endpoint.
+	MetricsConfig struct {
+		Token Secret // Bearer token scrapers send to read the metrics, served to anyone when empty.
+	}
+
+	// RequestLogConfig holds the settings of the access log written by the logging middleware.
+	RequestLogConfig struct {
+		Body         bool     // Logs the request body, when its content type is listed in ContentTypes.
+		MaxBodySize  int      `validate:"gte=0"`                                                         // Bodies larger than this many bytes are not logged, 4KB when zero.
+		ContentTypes []string `validate:"dive,oneof=application/json application/x-www-form-urlencoded"` // Media types whose body is logged, both when empty. Other bodies can not be redacted.
+		RedactFields []string // Body fields replaced by "[REDACTED]", matched case-insensitively.
+		SampleRatio  float64  `validate:"gte=0,lte=1"` // Fraction of successful requests logged. Failed requests are always logged.
+		SkipPaths    []string // Paths never logged, such as the probes.
+	}
+
+	// IdempotencyConfig holds the settings of the Idempotency-Key middleware.
+	IdempotencyConfig struct {
+		Enable      bool
+		Methods     []string      `validate:"dive,oneof=POST PUT PATCH DELETE"` // Methods the middleware applies to, POST when empty.
+		TTL         time.Duration `default:"24h"`                               // How long the first response is kept for replays.
+		LockTTL     time.Duration `default:"1m"`                                // How long an in-flight request holds its key, renewed while it runs.
+		MaxBodySize int64         `default:"1048576" validate:"gte=0"`          // Largest body, in bytes, of a request with an Idempotency-Key. Larger ones are rejected with 413.
+	}
+
+	// RateLimitConfig holds the default request limit and the per-route overrides.
+	RateLimitConfig struct {
+		Enable bool
+		Limit  int              `validate:"gte=0"`                           // Requests allowed per window and key.
+		Window time.Duration    `default:"1m"`                               // Length of the sliding window.
+		KeyBy  string           `validate:"omitempty,oneof=ip user api_key"` // What requests are counted by: "ip" (default), "user" or "api_key".
+		Routes []RateLimitRoute `validate:"dive"`
+	}
+
+	// RateLimitRoute overrides the default limit for a single route.
+	RateLimitRoute struct {
+		Method string
+		Path   string        `validate:"required"` // Route path as registered, e.g. /api/v1/users/:id.
+		Limit  int           `validate:"gte=0"`
+		Window time.Duration // Default window when zero.
+		KeyBy  string        `validate:"omitempty,oneof=ip user api_key"` // Default key when empty.
+	}
+
+	// FeaturesConfig holds the feature flags, see pkg/featureflag.
+	FeaturesConfig struct {
+		Redis    bool                   // Flags stored in Redis take precedence over Flags.
+		CacheTTL time.Duration          `default:"10s"`                 // How long the flags read from Redis are reused.
+		Flags    map[string]FeatureFlag `validate:"dive" reload:"true"` // Keyed by flag name, in lower case.
+	}
+
+	// FeatureFlag describes who a feature is on for, it is off for everyone when zero.
+	FeatureFlag struct {
+		Enable     bool     // On for everyone.
+		Users      []string // On for these users.
+		Percentage float64  `validate:"gte=0,lte=100"` // On for this share of the users.
 	}
 
 	JWTConfig struct {
-		Key     string
-		Expired int
+		Key     Secret `validate:"required"`            // Signs the HS256 tokens of the users.
+		Expired int    `default:"3600" validate:"gt=0"` // Longest lifetime of a token, in seconds.
 		Label   string
 	}
 )
 
-func NewLoadConfig() *Config {
-	config := &Config{}
+// NewLoadConfig loads config/config.yaml and the overlay of its environment.
+func NewLoadConfig() (*Config, error) {
+	return Load("config", "config")
+}
 
-	cfg, err := config.LoadConfig("config")
+// LoadConfig loads the configuration file config/<filename>.yaml.
+func (cfg *Config) LoadConfig(filename string) (*Config, error) {
+	return Load("config", filename)
+}
+
+// LoadConfigPath loads the configuration file <path>.yaml, relative to the working directory.
+func (cfg *Config) LoadConfigPath(path string) (Config, error) {
+	c, err := Load(filepath.Dir(path), filepath.Base(path))
 	if err != nil {
-		panic(err)
+		return Config{}, err
 	}
-	return cfg
+	return *c, nil
 }
 
-// LoadConfig loads the configuration from the specified filename.
-func (cfg *Config) LoadConfig(filename string) (*Config, error) {
-	// Create a new Viper instance.
+// Load reads the configuration file <name>.yaml in dir, then merges <name>.<Environment>.yaml
+// over it when that file exists. Environment variables override both, a key a.b being read
+// from A_B, e.g. APP_ENVIRONMENT selects the overlay. Fields left empty take the value of
+// their default tag. Secrets referring to a file, file:///path, or to an environment variable,
+// env:NAME, are replaced by what they refer to (see ResolveSecret), and the result is validated.
+func Load(dir, name string) (*Config, error) {
 	v := viper.New()
-
-	// Set the configuration file name, path, and environment variable settings.
-	v.SetConfigName(fmt.Sprintf("config/%s", filename))
-	v.AddConfigPath(".")
+	v.AddConfigPath(dir)
+	v.SetConfigName(name)
 	v.AutomaticEnv()
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	bindKeys(v, reflect.TypeOf(Config{}), "")
 
-	// Read the configuration file.
 	if err := v.ReadInConfig(); err != nil {
-		fmt.Printf("Error reading config file: %v\n", err)
-		return &Config{}, err
+		var notFound viper.ConfigFileNotFoundError
+		if errors.As(err, &notFound) {
+			return nil, fmt.Errorf("config: %s.yaml not found in %s", name, dir)
+		}
+		return nil, fmt.Errorf("config: read %s: %w", v.ConfigFileUsed(), err)
 	}
 
-	// Unmarshal the configuration into the Config struct.
-	var config = new(Config)
-	if err := v.Unmarshal(&config); err != nil {
-		fmt.Printf("Error unmarshaling config: %v\n", err)
-		return &Config{}, err
+	if env := v.GetString("app.environment"); env != "" {
+		v.SetConfigName(name + "." + env)
+		if err := v.MergeInConfig(); err != nil {
+			var notFound viper.ConfigFileNotFoundError
+			if !errors.As(err, &notFound) {
+				return nil, fmt.Errorf("config: read %s: %w", v.ConfigFileUsed(), err)
+			}
+		}
+	}
+
+	// Unknown keys are rejected, so a misspelled key is not silently replaced by its default.
+	config := new(Config)
+	if err := v.UnmarshalExact(config); err != nil {
+		return nil, fmt.Errorf("config: decode: %w", err)
+	}
+
+	if err := resolveSecrets(config); err != nil {
+		return nil, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
 	}
 
 	return config, nil
 }
 
-// LoadConfigPath loads the configuration from the specified path.
-func (cfg *Config) LoadConfigPath(path string) (Config, error) {
-	// Create a new Viper instance.
-	v := viper.New()
-
-	// Set the configuration file name, path, and environment variable settings.
-	v.SetConfigName(path)
-	v.AddConfigPath(".")
-	v.AutomaticEnv()
-	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+// bindKeys registers every field of t with v, under its dotted path prefixed with prefix,
+// so the field can be set from the environment even when the file does not mention it.
+func bindKeys(v *viper.Viper, t reflect.Type, prefix string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := prefix + strings.ToLower(field.Name)
 
-	// Read the configuration file.
-	if err := v.ReadInConfig(); err != nil {
-		// Handle the case where the configuration file is not found.
-		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-			return Config{}, errors.New("config file not found")
+		if field.Type.Kind() == reflect.Struct {
+			bindKeys(v, field.Type, key+".")
+			continue
 		}
-		return Config{}, err
-	}
 
-	// Parse the configuration into the Config struct.
-	var c Config
-	if err := v.Unmarshal(&c); err != nil {
-		log.Printf("unable to decode into struct, %v", err)
-		return Config{}, err
+		if value, ok := field.Tag.Lookup("default"); ok {
+			v.SetDefault(key, value)
+		}
+		_ = v.BindEnv(key)
 	}
-
-	return c, nil
 }
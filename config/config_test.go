@@ -0,0 +1,257 @@
+package config_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nutsp/golang-clean-architecture/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const baseConfig = `
+App:
+  Name: app
+Database:
+  Host: 127.0.0.1
+  Name: test
+  User: root
+Redis:
+  Addr: 127.0.0.1:6379
+JWT:
+  Key: secret
+`
+
+// writeConfig writes the files, keyed by name, in a temporary directory and returns it.
+func writeConfig(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		writeFile(t, dir, name, content)
+	}
+	return dir
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+}
+
+func TestLoadDefaults(t *testing.T) {
+	dir := writeConfig(t, map[string]string{"config.yaml": baseConfig})
+
+	cfg, err := config.Load(dir, "config")
+
+	require.NoError(t, err)
+	assert.Equal(t, "http", cfg.App.Scheme)
+	assert.Equal(t, "local", cfg.App.Environment)
+	assert.Equal(t, "9090", cfg.Server.Port)
+	assert.Equal(t, 3306, cfg.Database.Port)
+	assert.Equal(t, "1M", cfg.Server.BodyLimit)
+	assert.Equal(t, 15*time.Second, cfg.Server.ReadTimeout)
+	assert.Equal(t, "DENY", cfg.Server.Headers.FrameOptions)
+	assert.Equal(t, 30*time.Second, cfg.Lifecycle.StopTimeout)
+	assert.Equal(t, 24*time.Hour, cfg.Idempotency.TTL)
+}
+
+func TestLoadOverlay(t *testing.T) {
+	dir := writeConfig(t, map[string]string{
+		"config.yaml": baseConfig + `
+Server:
+  Port: "8080"
+`,
+		"config.staging.yaml": `
+Database:
+  Host: db.staging
+`,
+	})
+
+	t.Run("merges the overlay of the environment", func(t *testing.T) {
+		t.Setenv("APP_ENVIRONMENT", "staging")
+
+		cfg, err := config.Load(dir, "config")
+
+		require.NoError(t, err)
+		assert.Equal(t, "db.staging", cfg.Database.Host)
+		assert.Equal(t, "test", cfg.Database.Name)
+		assert.Equal(t, "8080", cfg.Server.Port)
+	})
+
+	t.Run("environment variables override the files", func(t *testing.T) {
+		t.Setenv("APP_ENVIRONMENT", "staging")
+		t.Setenv("DATABASE_HOST", "db.env")
+		t.Setenv("LIFECYCLE_STOPTIMEOUT", "1m")
+
+		cfg, err := config.Load(dir, "config")
+
+		require.NoError(t, err)
+		assert.Equal(t, "db.env", cfg.Database.Host)
+		assert.Equal(t, time.Minute, cfg.Lifecycle.StopTimeout)
+	})
+}
+
+func TestLoadErrors(t *testing.T) {
+	t.Run("missing file", func(t *testing.T) {
+		_, err := config.Load(t.TempDir(), "config")
+
+		assert.ErrorContains(t, err, "config.yaml not found")
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		dir := writeConfig(t, map[string]string{"config.yaml": baseConfig + `
+Server:
+  Prot: "8080"
+`})
+
+		_, err := config.Load(dir, "config")
+
+		assert.ErrorContains(t, err, "'Server' has invalid keys: prot")
+	})
+
+	t.Run("lists every invalid field", func(t *testing.T) {
+		dir := writeConfig(t, map[string]string{"config.yaml": `
+App:
+  Scheme: ftp
+Database:
+  Host: 127.0.0.1
+  Port: 0
+  Name: test
+  User: root
+Redis:
+  Mode: sentinel
+  Addrs: [127.0.0.1:26379]
+Logger:
+  Outputs: [file]
+HttpClient:
+  CircuitBreaker:
+    Enable: true
+Lifecycle:
+  StopTimeout: 10s
+`})
+
+		_, err := config.Load(dir, "config")
+
+		var validationErr *config.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.ElementsMatch(t, []string{
+			"App.Name: is required",
+			`App.Scheme: must be one of [http https], got "ftp"`,
+			"Database.Port: must be at least 1",
+			"Redis.MasterName: is required when Mode is sentinel",
+			"Logger.File.Path: is required when Logger.Outputs contains file",
+			"JWT.Key: is required",
+			"HttpClient.CircuitBreaker.FailureThreshold: is required when Enable is true",
+			"Lifecycle.StopTimeout: must be at least Lifecycle.DrainTimeout + Health.ShutdownDelay (15s)",
+		}, validationErr.Problems)
+	})
+}
+
+func TestLoadServerChecks(t *testing.T) {
+	dir := writeConfig(t, map[string]string{
+		"config.yaml": baseConfig + `
+Server:
+  BodyLimit: 1 megabyte
+  TrustedProxies: [10.0.0.0/8, 10.0.0.1]
+  CORS:
+    AllowOrigins: ["*"]
+    AllowCredentials: true
+  Headers:
+    FrameOptions: ALLOW
+`,
+		"config.production.yaml": `
+Server:
+  CORS:
+    AllowCredentials: false
+`,
+	})
+	t.Setenv("APP_ENVIRONMENT", "production")
+
+	_, err := config.Load(dir, "config")
+
+	var validationErr *config.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.ElementsMatch(t, []string{
+		`Server.Headers.FrameOptions: must be one of [DENY SAMEORIGIN], got "ALLOW"`,
+		`Server.BodyLimit: must be a size such as 512K or 2M, got "1 megabyte"`,
+		`Server.TrustedProxies[1]: must be a CIDR such as 10.0.0.0/8, got "10.0.0.1"`,
+		"Server.CORS.AllowOrigins: must list the origins in production",
+	}, validationErr.Problems)
+}
+
+func TestLoadSecrets(t *testing.T) {
+	secrets := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(secrets, "db"), []byte("s3cret\n"), 0o600))
+	t.Setenv("JWT_KEY", "jwt-key")
+
+	t.Run("resolves files and environment variables", func(t *testing.T) {
+		dir := writeConfig(t, map[string]string{"config.yaml": baseConfig, "config.local.yaml": `
+Database:
+  Password: file://` + filepath.Join(secrets, "db") + `
+HttpClient:
+  Headers:
+    X-Api-Key: env:JWT_KEY
+  Services:
+    mailer:
+      Auth:
+        Type: bearer
+        Token: env:JWT_KEY
+`})
+
+		cfg, err := config.Load(dir, "config")
+
+		require.NoError(t, err)
+		assert.Equal(t, "s3cret", cfg.Database.Password.Value())
+		assert.Equal(t, "jwt-key", cfg.HttpClient.Services["mailer"].Auth.Token.Value())
+		assert.Equal(t, "jwt-key", cfg.HttpClient.Headers["x-api-key"].Value())
+		assert.NotContains(t, fmt.Sprintf("%v", cfg.HttpClient), "jwt-key")
+	})
+
+	t.Run("leaves the plain strings as written", func(t *testing.T) {
+		dir := writeConfig(t, map[string]string{"config.yaml": baseConfig, "config.local.yaml": `
+App:
+  Name: env:JWT_KEY
+`})
+
+		cfg, err := config.Load(dir, "config")
+
+		require.NoError(t, err)
+		assert.Equal(t, "env:JWT_KEY", cfg.App.Name)
+	})
+
+	t.Run("lists the unresolved references", func(t *testing.T) {
+		dir := writeConfig(t, map[string]string{"config.yaml": baseConfig, "config.local.yaml": `
+Database:
+  Password: file://` + filepath.Join(secrets, "missing") + `
+Authentication:
+  Key: env:UNSET_AUTH_KEY
+`})
+
+		_, err := config.Load(dir, "config")
+
+		var validationErr *config.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		require.Len(t, validationErr.Problems, 2)
+		assert.Contains(t, validationErr.Problems[0], "Database.Password: read secret:")
+		assert.Equal(t, "Authentication.Key: environment variable UNSET_AUTH_KEY is not set", validationErr.Problems[1])
+	})
+}
+
+func TestSecretRedacted(t *testing.T) {
+	cfg := config.Config{Database: config.DatabaseConfig{User: "root", Password: "1234"}}
+
+	for _, format := range []string{"%v", "%+v", "%#v", "%s"} {
+		assert.NotContains(t, fmt.Sprintf(format, cfg), "1234", format)
+	}
+
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"Password":"[REDACTED]"`)
+
+	assert.Equal(t, "1234", cfg.Database.Password.Value())
+	assert.Empty(t, config.Secret("").String())
+}
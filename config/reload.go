@@ -0,0 +1,224 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce groups the events of a single save, editors and Kubernetes
+// ConfigMap updates writing a file in several steps.
+const reloadDebounce = 100 * time.Millisecond
+
+// ReloadLogger is the part of observability.Logger used by the Reloader.
+type ReloadLogger interface {
+	Info(msg string, fields ...interface{})
+	Warn(msg string, fields ...interface{})
+	Error(msg string, fields ...interface{})
+}
+
+// Reloader keeps the configuration up to date with its files while the application runs.
+//
+// Only the fields tagged reload:"true", and the fields they contain, change at runtime.
+// The other fields, such as the database connection, are read once by components that
+// would have to be rebuilt: a change to them is ignored with a warning until the next restart.
+type Reloader struct {
+	dir  string
+	name string
+
+	reloadMu sync.Mutex // Serializes the reloads.
+
+	mu          sync.RWMutex
+	current     *Config
+	subscribers map[string][]func(cfg *Config)
+
+	logger  ReloadLogger
+	watcher *fsnotify.Watcher
+	done    chan struct{}
+}
+
+// NewReloader loads the configuration like Load.
+func NewReloader(dir, name string) (*Reloader, error) {
+	cfg, err := Load(dir, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Reloader{
+		dir:         dir,
+		name:        name,
+		current:     cfg,
+		subscribers: make(map[string][]func(cfg *Config)),
+		logger:      nopLogger{},
+	}, nil
+}
+
+// Current returns the configuration in effect. It must not be modified.
+func (r *Reloader) Current() *Config {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.current
+}
+
+// Subscribe calls fn with the new configuration whenever section, a field of Config
+// such as "RateLimit", changes. fn runs in the goroutine of the reload and must not block.
+func (r *Reloader) Subscribe(section string, fn func(cfg *Config)) {
+	if _, ok := reflect.TypeOf(Config{}).FieldByName(section); !ok {
+		panic(fmt.Sprintf("config: unknown section %q", section))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.subscribers[section] = append(r.subscribers[section], fn)
+}
+
+// Reload loads the files again and applies the reloadable changes. An invalid
+// configuration is returned as an error and leaves the current one in effect.
+func (r *Reloader) Reload() error {
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+
+	loaded, err := Load(r.dir, r.name)
+	if err != nil {
+		return err
+	}
+
+	next := *r.Current()
+	var sections, ignored []string
+	config := reflect.ValueOf(&next).Elem()
+	for i := 0; i < config.NumField(); i++ {
+		field := config.Type().Field(i)
+		changed, rejected := mergeReloadable(config.Field(i), reflect.ValueOf(loaded).Elem().Field(i), field.Name, field.Tag.Get("reload") == "true")
+		if changed {
+			sections = append(sections, field.Name)
+		}
+		ignored = append(ignored, rejected...)
+	}
+
+	if len(ignored) > 0 {
+		r.logger.Warn("Config.Reload", "ignored", ignored, "reason", "changing these fields requires a restart")
+	}
+	if len(sections) == 0 {
+		return nil
+	}
+
+	r.mu.Lock()
+	r.current = &next
+	var subscribers []func(cfg *Config)
+	for _, section := range sections {
+		subscribers = append(subscribers, r.subscribers[section]...)
+	}
+	r.mu.Unlock()
+
+	r.logger.Info("Config.Reload", "sections", sections)
+	for _, fn := range subscribers {
+		fn(&next)
+	}
+
+	return nil
+}
+
+// mergeReloadable sets dst to src when reloadable, otherwise merges the reloadable fields
+// of src into dst. It reports whether dst changed, and the paths of the fields that differ
+// but cannot be reloaded.
+func mergeReloadable(dst, src reflect.Value, path string, reloadable bool) (bool, []string) {
+	if reflect.DeepEqual(dst.Interface(), src.Interface()) {
+		return false, nil
+	}
+	if reloadable {
+		dst.Set(src)
+		return true, nil
+	}
+	if dst.Kind() != reflect.Struct {
+		return false, []string{path}
+	}
+
+	var changed bool
+	var rejected []string
+	for i := 0; i < dst.NumField(); i++ {
+		field := dst.Type().Field(i)
+		fieldChanged, fieldRejected := mergeReloadable(dst.Field(i), src.Field(i), path+"."+field.Name, field.Tag.Get("reload") == "true")
+		changed = changed || fieldChanged
+		rejected = append(rejected, fieldRejected...)
+	}
+	return changed, rejected
+}
+
+// Watch reloads the configuration whenever one of its files changes, until Close.
+// Reload errors and ignored changes are reported to logger.
+func (r *Reloader) Watch(logger ReloadLogger) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("config: watch: %w", err)
+	}
+	// The directory is watched rather than the files, which editors and Kubernetes replace.
+	if err := watcher.Add(r.dir); err != nil {
+		watcher.Close()
+		return fmt.Errorf("config: watch %s: %w", r.dir, err)
+	}
+
+	r.logger = logger
+	r.watcher = watcher
+	r.done = make(chan struct{})
+	go r.watch()
+
+	return nil
+}
+
+// Close stops watching the files.
+func (r *Reloader) Close() error {
+	if r.watcher == nil {
+		return nil
+	}
+
+	err := r.watcher.Close()
+	<-r.done
+	return err
+}
+
+func (r *Reloader) watch() {
+	defer close(r.done)
+
+	var reload <-chan time.Time
+	for {
+		select {
+		case event, ok := <-r.watcher.Events:
+			if !ok {
+				return
+			}
+			if r.isConfigFile(event.Name) {
+				reload = time.After(reloadDebounce)
+			}
+		case err, ok := <-r.watcher.Errors:
+			if !ok {
+				return
+			}
+			r.logger.Error("Config.Watch", "error", err)
+		case <-reload:
+			reload = nil
+			if err := r.Reload(); err != nil {
+				r.logger.Error("Config.Reload", "error", err)
+			}
+		}
+	}
+}
+
+// isConfigFile reports whether path is the base file, an overlay, or the data
+// directory Kubernetes swaps when a mounted ConfigMap changes.
+func (r *Reloader) isConfigFile(path string) bool {
+	base := filepath.Base(path)
+	return base == "..data" || strings.HasPrefix(base, r.name+".")
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Warn(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
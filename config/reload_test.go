@@ -0,0 +1,112 @@
+package config_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nutsp/golang-clean-architecture/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// warnings records the Warn lines of a Reloader.
+type warnings struct {
+	mu     sync.Mutex
+	fields [][]interface{}
+}
+
+func (w *warnings) Info(string, ...interface{})  {}
+func (w *warnings) Error(string, ...interface{}) {}
+
+func (w *warnings) Warn(_ string, fields ...interface{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.fields = append(w.fields, fields)
+}
+
+func (w *warnings) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return fmt.Sprint(w.fields)
+}
+
+func TestReloaderWatch(t *testing.T) {
+	dir := writeConfig(t, map[string]string{"config.yaml": baseConfig + `
+Logger:
+  Level: info
+RateLimit:
+  Limit: 100
+`})
+
+	reloader, err := config.NewReloader(dir, "config")
+	require.NoError(t, err)
+
+	logger := &warnings{}
+	require.NoError(t, reloader.Watch(logger))
+	defer reloader.Close()
+
+	reloaded := make(chan *config.Config, 2)
+	reloader.Subscribe("RateLimit", func(cfg *config.Config) { reloaded <- cfg })
+	reloader.Subscribe("Database", func(cfg *config.Config) { t.Error("the database section was reloaded") })
+
+	writeFile(t, dir, "config.yaml", `
+App:
+  Name: app
+Database:
+  Host: db.internal
+  Name: test
+  User: root
+Redis:
+  Addr: 127.0.0.1:6379
+JWT:
+  Key: secret
+Logger:
+  Level: warn
+  Encoding: console
+RateLimit:
+  Limit: 10
+`)
+
+	select {
+	case cfg := <-reloaded:
+		assert.Equal(t, 10, cfg.RateLimit.Limit)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the configuration was not reloaded")
+	}
+
+	cfg := reloader.Current()
+	assert.Equal(t, 10, cfg.RateLimit.Limit)
+	assert.Equal(t, "warn", cfg.Logger.Level)
+	// The other changes wait for a restart.
+	assert.Equal(t, "127.0.0.1", cfg.Database.Host)
+	assert.Equal(t, "json", cfg.Logger.Encoding)
+	assert.Contains(t, logger.String(), "[Database.Host Logger.Encoding]")
+}
+
+func TestReloaderReloadInvalid(t *testing.T) {
+	dir := writeConfig(t, map[string]string{"config.yaml": baseConfig})
+
+	reloader, err := config.NewReloader(dir, "config")
+	require.NoError(t, err)
+	before := reloader.Current()
+
+	writeFile(t, dir, "config.yaml", baseConfig+`
+RateLimit:
+  KeyBy: session
+`)
+
+	var validationErr *config.ValidationError
+	assert.ErrorAs(t, reloader.Reload(), &validationErr)
+	assert.Same(t, before, reloader.Current())
+}
+
+func TestReloaderSubscribeUnknownSection(t *testing.T) {
+	reloader, err := config.NewReloader(writeConfig(t, map[string]string{"config.yaml": baseConfig}), "config")
+	require.NoError(t, err)
+
+	assert.PanicsWithValue(t, `config: unknown section "Ratelimit"`, func() {
+		reloader.Subscribe("Ratelimit", func(*config.Config) {})
+	})
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+const (
+	secretFilePrefix = "file://"
+	secretEnvPrefix  = "env:"
+	redacted         = "[REDACTED]"
+)
+
+var secretType = reflect.TypeOf(Secret(""))
+
+// Secret is a configuration value that must not be disclosed. It prints, logs and marshals
+// as [REDACTED] when set, Value returns the value itself.
+type Secret string
+
+// Value returns the secret in clear, for the code handing it to a client or a driver.
+func (s Secret) Value() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return fmt.Sprintf("%q", s.String())
+}
+
+// MarshalText is used by the JSON and YAML encoders, so a marshaled config is redacted too.
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// ResolveSecret returns the value ref refers to: the content of the file for file:///path,
+// the environment variable NAME for env:NAME. Any other value is returned as is.
+func ResolveSecret(ref string) (string, error) {
+	switch {
+	case strings.HasPrefix(ref, secretFilePrefix):
+		path := strings.TrimPrefix(ref, secretFilePrefix)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("read secret: %w", err)
+		}
+		// Secret files are usually written with a trailing newline.
+		return strings.TrimRight(string(data), "\r\n"), nil
+	case strings.HasPrefix(ref, secretEnvPrefix):
+		name := strings.TrimPrefix(ref, secretEnvPrefix)
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return value, nil
+	default:
+		return ref, nil
+	}
+}
+
+// resolveSecrets replaces the references in every Secret of cfg by the value they refer to.
+// Plain strings are kept as written, a resolved value would print in clear. It returns a *ValidationError listing the references that could not be resolved.
+func resolveSecrets(cfg *Config) error {
+	var problems []string
+	resolveValue(reflect.ValueOf(cfg).Elem(), "", &problems)
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+func resolveValue(v reflect.Value, path string, problems *[]string) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			resolveValue(v.Elem(), path, problems)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if field := v.Type().Field(i); field.IsExported() {
+				resolveValue(v.Field(i), strings.TrimPrefix(path+"."+field.Name, "."), problems)
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			resolveValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i), problems)
+		}
+	case reflect.Map:
+		// Map elements are not addressable, they are resolved in a copy set back in the map.
+		for _, key := range v.MapKeys() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(key))
+			resolveValue(elem, fmt.Sprintf("%s[%v]", path, key), problems)
+			v.SetMapIndex(key, elem)
+		}
+	case reflect.String:
+		if v.Type() != secretType {
+			return
+		}
+		value, err := ResolveSecret(v.String())
+		if err != nil {
+			*problems = append(*problems, path+": "+err.Error())
+			return
+		}
+		v.SetString(value)
+	}
+}
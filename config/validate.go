@@ -0,0 +1,104 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/gommon/bytes"
+)
+
+// ValidationError lists every invalid field of a configuration, so all of them
+// can be fixed at once.
+type ValidationError struct {
+	Problems []string // "<Section>.<Field>: <problem>"
+}
+
+func (e *ValidationError) Error() string {
+	return "config: invalid configuration:\n  " + strings.Join(e.Problems, "\n  ")
+}
+
+// Validate checks the validate tags of the configuration and the constraints spanning
+// several sections. It returns a *ValidationError listing every problem found.
+func (cfg *Config) Validate() error {
+	var problems []string
+
+	var errs validator.ValidationErrors
+	if err := validator.New().Struct(cfg); err != nil {
+		if !errors.As(err, &errs) {
+			return fmt.Errorf("config: validate: %w", err)
+		}
+	}
+	for _, fe := range errs {
+		field := strings.TrimPrefix(fe.Namespace(), "Config.")
+		problems = append(problems, field+": "+describe(fe))
+	}
+
+	if _, err := bytes.Parse(cfg.Server.BodyLimit); err != nil {
+		problems = append(problems, fmt.Sprintf("Server.BodyLimit: must be a size such as 512K or 2M, got %q", cfg.Server.BodyLimit))
+	}
+	for _, origin := range cfg.Server.CORS.AllowOrigins {
+		if origin != "*" {
+			continue
+		}
+		// Browsers refuse credentials with "*", so the origin of the request would have to be echoed back.
+		if cfg.Server.CORS.AllowCredentials {
+			problems = append(problems, "Server.CORS.AllowOrigins: must list the origins when Server.CORS.AllowCredentials is true")
+		}
+		if cfg.App.Environment == "production" {
+			problems = append(problems, "Server.CORS.AllowOrigins: must list the origins in production")
+		}
+		break
+	}
+	if cfg.Observability.Enable && cfg.Observability.Mode == "" {
+		problems = append(problems, "Observability.Mode: is required when Observability.Enable is true")
+	}
+	for _, output := range cfg.Logger.Outputs {
+		if output == "file" && cfg.Logger.File.Path == "" {
+			problems = append(problems, "Logger.File.Path: is required when Logger.Outputs contains file")
+			break
+		}
+	}
+	// The server stops within StopTimeout, after failing readiness for ShutdownDelay
+	// and draining the requests for up to DrainTimeout.
+	if cfg.Lifecycle.StopTimeout > 0 && cfg.Lifecycle.DrainTimeout+cfg.Health.ShutdownDelay > cfg.Lifecycle.StopTimeout {
+		problems = append(problems, fmt.Sprintf("Lifecycle.StopTimeout: must be at least Lifecycle.DrainTimeout + Health.ShutdownDelay (%s)",
+			cfg.Lifecycle.DrainTimeout+cfg.Health.ShutdownDelay))
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// describe explains in words why fe failed.
+func describe(fe validator.FieldError) string {
+	param := fe.Param()
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "required_if":
+		field, value, _ := strings.Cut(param, " ")
+		return fmt.Sprintf("is required when %s is %s", field, value)
+	case "required_with":
+		return fmt.Sprintf("is required when %s is set", param)
+	case "required_without":
+		return fmt.Sprintf("is required when %s is empty", param)
+	case "oneof":
+		return fmt.Sprintf("must be one of [%s], got %q", param, fmt.Sprint(fe.Value()))
+	case "min", "gte":
+		return "must be at least " + param
+	case "max", "lte":
+		return "must be at most " + param
+	case "numeric":
+		return fmt.Sprintf("must be numeric, got %q", fmt.Sprint(fe.Value()))
+	case "url":
+		return fmt.Sprintf("must be a URL, got %q", fmt.Sprint(fe.Value()))
+	case "cidr":
+		return fmt.Sprintf("must be a CIDR such as 10.0.0.0/8, got %q", fmt.Sprint(fe.Value()))
+	default:
+		return fmt.Sprintf("fails the %s check", fe.Tag())
+	}
+}
@@ -2,7 +2,10 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
@@ -12,55 +15,172 @@ import (
 	"github.com/nutsp/golang-clean-architecture/config"
 	"github.com/nutsp/golang-clean-architecture/internal/handlers"
 	"github.com/nutsp/golang-clean-architecture/internal/middlewares"
+	"github.com/nutsp/golang-clean-architecture/internal/module"
+	"github.com/nutsp/golang-clean-architecture/pkg/health"
+	"github.com/nutsp/golang-clean-architecture/pkg/lifecycle"
+	"github.com/nutsp/golang-clean-architecture/pkg/metrics"
 	"github.com/nutsp/golang-clean-architecture/pkg/observability"
 	"go.uber.org/dig"
 )
 
 type App struct {
-	echo        *echo.Echo
-	config      *config.Config
-	logger      observability.Logger
-	middleware  middlewares.IMiddleware
-	userHandler handlers.IUserHandler
+	echo          *echo.Echo
+	config        *config.Config
+	logger        observability.Logger
+	lifecycle     *lifecycle.Lifecycle
+	metrics       *metrics.Registry
+	health        *health.Health
+	middleware    middlewares.IMiddleware
+	routes        []module.Routes
+	healthHandler handlers.IHealthHandler
+	adminHandler  handlers.IAdminHandler
+	serveErr      chan error // Receives the error of the server when it stops on its own.
 }
 
 type AppDependencies struct {
 	dig.In
-	Config      *config.Config
-	Logger      observability.Logger    `name:"Logger"`
-	Middleware  middlewares.IMiddleware `name:"Middleware"`
-	UserHandler handlers.IUserHandler   `name:"UserHandler"`
+	Config        *config.Config
+	Logger        observability.Logger `name:"Logger"`
+	Lifecycle     *lifecycle.Lifecycle
+	Tracer        *observability.TracerProvider // Unused, but installs the global tracer provider before the components that trace.
+	Metrics       *metrics.Registry
+	Health        *health.Health
+	Middleware    middlewares.IMiddleware `name:"Middleware"`
+	HealthHandler handlers.IHealthHandler `name:"HealthHandler"`
+	AdminHandler  handlers.IAdminHandler  `name:"AdminHandler"`
+	Routes        []module.Routes         `group:"routes"`
+	Middlewares   []module.Middleware     `group:"middlewares"`
 }
 
-func NewApp(deps AppDependencies) {
+// Exit codes of the process.
+const (
+	ExitOK              = 0
+	ExitFailure         = 1 // The application failed to start or the server failed.
+	ExitUncleanShutdown = 2 // The application was asked to stop but did not stop cleanly.
+)
+
+const (
+	defaultStartTimeout = 15 * time.Second
+	defaultStopTimeout  = 30 * time.Second
+	defaultDrainTimeout = 10 * time.Second
+)
+
+// ErrServe wraps the error of the HTTP server when it stops on its own.
+var ErrServe = errors.New("app: server failed")
+
+func NewApp(deps AppDependencies) *App {
 	app := &App{
-		echo:        middlewares.NewEchoServer(deps.Config, deps.Middleware),
-		config:      deps.Config,
-		middleware:  deps.Middleware,
-		userHandler: deps.UserHandler,
+		echo:          middlewares.NewEchoServer(deps.Config, deps.Middleware),
+		config:        deps.Config,
+		logger:        deps.Logger,
+		lifecycle:     deps.Lifecycle,
+		metrics:       deps.Metrics,
+		health:        deps.Health,
+		middleware:    deps.Middleware,
+		routes:        deps.Routes,
+		healthHandler: deps.HealthHandler,
+		adminHandler:  deps.AdminHandler,
+		serveErr:      make(chan error, 1),
 	}
-	app.Start()
-}
 
-func (app *App) Start() error {
-	app.InitRoute()
+	module.SortMiddlewares(deps.Middlewares)
+	for _, m := range deps.Middlewares {
+		app.echo.Use(m.Func)
+	}
+
+	// The server is appended last, so it starts once every dependency is up
+	// and stops first, before the resources used by in-flight requests are closed.
+	app.lifecycle.Append(lifecycle.Hook{
+		Name:    "http",
+		OnStart: app.startServer,
+		OnStop:  app.stopServer,
+	})
 
-	return app.Run()
+	return app
 }
 
+// Run starts every component and serves until SIGINT or SIGTERM, or until the server fails,
+// then stops every component. ExitCode maps the returned error to the exit code of the process.
 func (app *App) Run() error {
+	cfg := app.config.Lifecycle
+
+	startCtx, cancel := context.WithTimeout(context.Background(), durationOr(cfg.StartTimeout, defaultStartTimeout))
+	err := app.lifecycle.Start(startCtx)
+	cancel()
+	if err != nil {
+		return err
+	}
+
 	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGTERM)
-	signal.Notify(quit, syscall.SIGINT)
+	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(quit)
 
-	go func() {
-		<-quit
+	var serveErr error
+	select {
+	case sig := <-quit:
+		app.logger.Info("App.Run", "signal", sig.String())
+	case err := <-app.serveErr:
+		serveErr = fmt.Errorf("%w: %w", ErrServe, err)
+		app.logger.Error("App.Run", "error", err)
+	}
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), durationOr(cfg.StopTimeout, defaultStopTimeout))
+	defer cancel()
 
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
+	return errors.Join(serveErr, app.lifecycle.Stop(stopCtx))
+}
 
-		app.echo.Shutdown(ctx)
+// ExitCode returns the exit code of the process for the error returned by Run.
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, lifecycle.ErrStop) && !errors.Is(err, lifecycle.ErrStart) && !errors.Is(err, ErrServe):
+		return ExitUncleanShutdown
+	default:
+		return ExitFailure
+	}
+}
+
+// startServer listens before returning, so a port already in use fails the start.
+func (app *App) startServer(ctx context.Context) error {
+	app.InitRoute()
+
+	var lc net.ListenConfig
+	listener, err := lc.Listen(ctx, "tcp", fmt.Sprintf(":%s", app.config.Server.Port))
+	if err != nil {
+		return err
+	}
+	app.echo.Listener = listener
+
+	go func() {
+		if err := app.echo.Start(""); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			app.serveErr <- err
+		}
 	}()
 
-	return app.echo.Start(fmt.Sprintf(":%s", app.config.Server.Port))
+	return nil
+}
+
+// stopServer fails readiness, gives load balancers time to stop routing to this instance,
+// then waits for the in-flight requests.
+func (app *App) stopServer(ctx context.Context) error {
+	app.health.SetShuttingDown()
+
+	select {
+	case <-time.After(app.config.Health.ShutdownDelay):
+	case <-ctx.Done():
+	}
+
+	drainCtx, cancel := context.WithTimeout(ctx, durationOr(app.config.Lifecycle.DrainTimeout, defaultDrainTimeout))
+	defer cancel()
+
+	return app.echo.Shutdown(drainCtx)
+}
+
+func durationOr(d, fallback time.Duration) time.Duration {
+	if d <= 0 {
+		return fallback
+	}
+	return d
 }
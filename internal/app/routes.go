@@ -1,10 +1,26 @@
 package app
 
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/nutsp/golang-clean-architecture/internal/module"
+)
+
 func (app *App) InitRoute() {
-	api := app.echo.Group("/api")
+	app.echo.GET("/metrics", echo.WrapHandler(app.metrics.Handler()), app.middleware.MetricsAccessMiddleware())
+	app.echo.GET("/healthz", app.healthHandler.LivenessHandler)
+	app.echo.GET("/readyz", app.healthHandler.ReadinessHandler)
+
+	if app.config.Admin.Token != "" {
+		admin := app.echo.Group("/admin", app.middleware.AdminMiddleware())
+		admin.GET("/log-level", app.adminHandler.GetLogLevelHandler)
+		admin.PUT("/log-level", app.adminHandler.SetLogLevelHandler)
+	}
 
-	v1 := api.Group("/v1")
-	v1.POST("/users", app.userHandler.CreateUserHandler)
-	v1.PUT("/users", app.userHandler.UpdateUserHandler)
-	v1.GET("/users/:id", app.userHandler.GetUserInfoHandler)
+	router := module.Router{
+		Echo: app.echo,
+		V1:   app.echo.Group("/api/v1"),
+	}
+	for _, routes := range app.routes {
+		routes(router)
+	}
 }
@@ -1,16 +1,26 @@
 package container
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
 	"github.com/nutsp/golang-clean-architecture/config"
 	"github.com/nutsp/golang-clean-architecture/internal/app"
+	"github.com/nutsp/golang-clean-architecture/internal/features"
 	"github.com/nutsp/golang-clean-architecture/internal/handlers"
 	"github.com/nutsp/golang-clean-architecture/internal/infastructure/database"
 	"github.com/nutsp/golang-clean-architecture/internal/middlewares"
-	"github.com/nutsp/golang-clean-architecture/internal/repositories"
-	"github.com/nutsp/golang-clean-architecture/internal/usecase"
+	"github.com/nutsp/golang-clean-architecture/internal/module"
 	"github.com/nutsp/golang-clean-architecture/pkg/datasource"
+	"github.com/nutsp/golang-clean-architecture/pkg/health"
 	httpClient "github.com/nutsp/golang-clean-architecture/pkg/httpclient"
+	"github.com/nutsp/golang-clean-architecture/pkg/lifecycle"
+	"github.com/nutsp/golang-clean-architecture/pkg/metrics"
 	"github.com/nutsp/golang-clean-architecture/pkg/observability"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"go.uber.org/dig"
 )
 
@@ -19,10 +29,92 @@ type Container struct {
 	Error     error
 }
 
-type Dependency struct {
-	Constructor interface{}
-	Interface   interface{}
-	Token       string
+type httpClientDependencies struct {
+	dig.In
+	Config  *config.Config
+	Logger  observability.Logger `name:"Logger"`
+	Metrics *metrics.ClientMetrics
+}
+
+// interceptors returns the interceptors shared by every outbound HTTP client.
+func (deps httpClientDependencies) interceptors() httpClient.Option {
+	return httpClient.WithInterceptors(
+		httpClient.Tracing(),
+		httpClient.CorrelationID(),
+		httpClient.Logging(deps.Logger),
+		httpClient.Metrics(deps.Metrics),
+	)
+}
+
+type metricsDependencies struct {
+	dig.In
+	Collectors []prometheus.Collector `group:"metrics"`
+}
+
+type databaseMetricsDependencies struct {
+	dig.In
+	Database database.IDatabase `name:"Database"`
+}
+
+// collectors returns a collector of the pool stats of every database connection.
+func (deps databaseMetricsDependencies) collectors() ([]prometheus.Collector, error) {
+	pools, err := deps.Database.Pools()
+	if err != nil {
+		return nil, err
+	}
+
+	cs := make([]prometheus.Collector, 0, len(pools))
+	for name, pool := range pools {
+		cs = append(cs, collectors.NewDBStatsCollector(pool, name))
+	}
+	return cs, nil
+}
+
+type healthDependencies struct {
+	dig.In
+	Config   *config.Config
+	Checkers []health.Checker `group:"health"`
+}
+
+type databaseHealthDependencies struct {
+	dig.In
+	Database database.IDatabase `name:"Database"`
+}
+
+// checkers returns a checker pinging every database connection.
+func (deps databaseHealthDependencies) checkers() ([]health.Checker, error) {
+	pools, err := deps.Database.Pools()
+	if err != nil {
+		return nil, err
+	}
+
+	checkers := make([]health.Checker, 0, len(pools))
+	for name, pool := range pools {
+		checkers = append(checkers, health.Checker{
+			Name:  "database:" + name,
+			Check: pool.PingContext,
+		})
+	}
+	return checkers, nil
+}
+
+type redisHealthDependencies struct {
+	dig.In
+	RedisClient datasource.IRedisClient `name:"RedisClient"`
+}
+
+func (deps redisHealthDependencies) checkers() []health.Checker {
+	return []health.Checker{{
+		Name:  "redis",
+		Check: deps.RedisClient.Ping,
+	}}
+}
+
+// closeHook adapts the Close method of c to an OnStop hook.
+func closeHook(c io.Closer) func(context.Context) error {
+	return func(context.Context) error {
+		return c.Close()
+	}
 }
 
 func NewContainer() *Container {
@@ -32,47 +124,257 @@ func NewContainer() *Container {
 	return c
 }
 
-func (cn *Container) Run() *Container {
-	err := cn.container.Invoke(app.NewApp)
-	if err != nil {
-		panic(err)
+// Run validates the graph, then builds the application and runs it until it stops.
+// app.ExitCode maps the returned error to the exit code of the process.
+func (cn *Container) Run() error {
+	if err := cn.Validate(); err != nil {
+		return err
+	}
+
+	return cn.container.Invoke(func(a *app.App) error {
+		return a.Run()
+	})
+}
+
+// Validate reports every provider that failed to register, then resolves the whole graph
+// of the application without calling any constructor, so a missing dependency or a cycle
+// fails the boot before any connection is opened.
+func (cn *Container) Validate() error {
+	if cn.Error != nil {
+		return cn.Error
 	}
 
-	return cn
+	_, err := dryRun()
+	return err
+}
+
+// PrintGraph writes the dependency graph in the DOT format, with the failing
+// constructors highlighted. It returns the validation error, if any.
+func (cn *Container) PrintGraph(w io.Writer) error {
+	c, err := dryRun()
+	if err := dig.Visualize(c, w, dig.VisualizeError(err)); err != nil {
+		return err
+	}
+	return err
+}
+
+// dryRun returns a container where constructors are registered but never called,
+// after resolving the application in it.
+func dryRun() (*dig.Container, error) {
+	c := dig.New(dig.DryRun(true))
+	if err := configure(c); err != nil {
+		return c, err
+	}
+	return c, c.Invoke(func(*app.App) {})
 }
 
 func (cn *Container) Configure() {
 	cn.container = dig.New()
+	cn.Error = configure(cn.container)
+}
+
+// configure registers the dependencies of the application and of every feature module in c.
+func configure(c *dig.Container) error {
+	errs := []error{provide(c, dependencies())}
+	for _, m := range features.Modules() {
+		errs = append(errs, provideModule(c, m))
+	}
+	return errors.Join(errs...)
+}
+
+// provideModule registers the dependencies of m, and its routes and middlewares in their value groups.
+func provideModule(c *dig.Container, m module.Module) error {
+	deps := m.Dependencies
+	if m.Routes != nil {
+		deps = append(deps, module.Dependency{Constructor: m.Routes, Group: module.GroupRoutes})
+	}
+	if m.Middlewares != nil {
+		deps = append(deps, module.Dependency{Constructor: m.Middlewares, Group: module.GroupMiddlewares + ",flatten"})
+	}
+
+	if err := provide(c, deps); err != nil {
+		return fmt.Errorf("module %s: %w", m.Name, err)
+	}
+	return nil
+}
+
+// provide registers deps in c and reports every registration that failed.
+func provide(c *dig.Container, deps []module.Dependency) error {
+	var errs []error
+	for _, dep := range deps {
+		var err error
+		if dep.Group != "" {
+			err = c.Provide(dep.Constructor, dig.Group(dep.Group))
+		} else if dep.Interface != nil {
+			err = c.Provide(dep.Constructor, dig.As(dep.Interface), dig.Name(dep.Token))
+		} else {
+			err = c.Provide(dep.Constructor)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
 
-	deps := []Dependency{
+// dependencies returns the constructors shared by the feature modules.
+func dependencies() []module.Dependency {
+	return []module.Dependency{
+		{
+			Constructor: func() (*config.Reloader, error) {
+				return config.NewReloader("config", "config")
+			},
+		},
+		{
+			// The configuration at boot. Components following the reloads subscribe to the Reloader.
+			Constructor: func(reloader *config.Reloader) *config.Config {
+				return reloader.Current()
+			},
+		},
+		{
+			Constructor: func(cfg *config.Config, reloader *config.Reloader) *observability.ZapLogger {
+				logger := observability.NewZapLogger(cfg.Logger)
+				reloader.Subscribe("Logger", func(cfg *config.Config) {
+					level := cfg.Logger.Level
+					if level == "" {
+						level = "debug"
+					}
+					if err := logger.SetLevel(level); err != nil {
+						logger.Error("Config.Reload", "section", "Logger", "error", err)
+					}
+				})
+				return logger
+			},
+		},
+		{
+			// The logger is the first hook, so its buffered lines are flushed last.
+			Constructor: func(logger *observability.ZapLogger, reloader *config.Reloader) *lifecycle.Lifecycle {
+				lc := lifecycle.New(logger)
+				lc.Append(lifecycle.Hook{
+					Name: "logger",
+					OnStop: func(context.Context) error {
+						// Syncing a terminal fails on some platforms, there is nothing to flush there anyway.
+						logger.Sync()
+						return nil
+					},
+				})
+				lc.Append(lifecycle.Hook{
+					Name: "config",
+					OnStart: func(context.Context) error {
+						return reloader.Watch(logger)
+					},
+					OnStop: func(context.Context) error {
+						return reloader.Close()
+					},
+				})
+				return lc
+			},
+		},
+		{
+			Constructor: func(logger *observability.ZapLogger) *observability.ZapLogger { return logger },
+			Interface:   new(observability.Logger),
+			Token:       "Logger",
+		},
 		{
-			Constructor: config.NewLoadConfig,
+			Constructor: func(logger *observability.ZapLogger) *observability.ZapLogger { return logger },
+			Interface:   new(observability.LevelController),
+			Token:       "LogLevel",
 		},
 		{
-			Constructor: func(cfg *config.Config) *observability.ZapLogger {
-				return observability.NewZapLogger(cfg.Logger)
+			Constructor: func(cfg *config.Config, lc *lifecycle.Lifecycle) (*observability.TracerProvider, error) {
+				tp, err := observability.NewTracerProvider(cfg.App, cfg.Observability)
+				if err != nil {
+					return nil, err
+				}
+				// Flushes the spans of the last requests.
+				lc.Append(lifecycle.Hook{Name: "tracer", OnStop: tp.Shutdown})
+				return tp, nil
 			},
-			Interface: new(observability.Logger),
-			Token:     "Logger",
 		},
 		{
-			Constructor: func(cfg *config.Config) *httpClient.Client {
-				return httpClient.NewClient(cfg.HttpClient)
+			Constructor: func(deps httpClientDependencies) *httpClient.Client {
+				return httpClient.NewClient(deps.Config.HttpClient, deps.interceptors())
 			},
 			Interface: new(httpClient.IClient),
 			Token:     "HttpClient",
 		},
 		{
-			Constructor: func(cfg *config.Config) *datasource.RedisClient {
-				return datasource.NewRedisClient(cfg.Redis)
+			Constructor: func(deps httpClientDependencies) (*httpClient.Client, error) {
+				return httpClient.NewServiceClient(deps.Config.HttpClient, "mailer", deps.interceptors())
+			},
+			Interface: new(httpClient.IClient),
+			Token:     "MailerHttpClient",
+		},
+		{
+			Constructor: metrics.NewHTTPMetrics,
+		},
+		{
+			Constructor: metrics.NewClientMetrics,
+		},
+		{
+			Constructor: metrics.NewRedisMetrics,
+		},
+		{
+			Constructor: metrics.NewCacheMetrics,
+		},
+		{
+			Constructor: func(m *metrics.HTTPMetrics) prometheus.Collector { return m },
+			Group:       "metrics",
+		},
+		{
+			Constructor: func(m *metrics.ClientMetrics) prometheus.Collector { return m },
+			Group:       "metrics",
+		},
+		{
+			Constructor: func(m *metrics.RedisMetrics) prometheus.Collector { return m },
+			Group:       "metrics",
+		},
+		{
+			Constructor: func(m *metrics.CacheMetrics) prometheus.Collector { return m },
+			Group:       "metrics",
+		},
+		{
+			Constructor: databaseMetricsDependencies.collectors,
+			Group:       "metrics,flatten",
+		},
+		{
+			Constructor: func(deps metricsDependencies) (*metrics.Registry, error) {
+				return metrics.NewRegistry(deps.Collectors...)
+			},
+		},
+		{
+			Constructor: databaseHealthDependencies.checkers,
+			Group:       "health,flatten",
+		},
+		{
+			Constructor: redisHealthDependencies.checkers,
+			Group:       "health,flatten",
+		},
+		{
+			Constructor: func(deps healthDependencies) *health.Health {
+				return health.New(deps.Config.Health.Timeout, deps.Config.Health.CacheTTL, deps.Checkers...)
+			},
+		},
+		{
+			Constructor: func(cfg *config.Config, m *metrics.RedisMetrics, lc *lifecycle.Lifecycle) (*datasource.RedisClient, error) {
+				client, err := datasource.NewRedisClient(cfg.Redis, datasource.KeyNamespace(cfg.App, cfg.Redis), datasource.RedisMetricsHook(m))
+				if err != nil {
+					return nil, err
+				}
+				lc.Append(lifecycle.Hook{Name: "redis", OnStop: closeHook(client)})
+				return client, nil
 			},
 			Interface: new(datasource.IRedisClient),
 			Token:     "RedisClient",
 		},
 		{
-			Constructor: database.NewDatabase,
-			Interface:   new(database.IDatabase),
-			Token:       "Database",
+			Constructor: func(cfg *config.Config, lc *lifecycle.Lifecycle) *database.Database {
+				db := database.NewDatabase(cfg)
+				lc.Append(lifecycle.Hook{Name: "database", OnStop: closeHook(db)})
+				return db
+			},
+			Interface: new(database.IDatabase),
+			Token:     "Database",
 		},
 		{
 			Constructor: middlewares.NewMiddleware,
@@ -80,39 +382,17 @@ func (cn *Container) Configure() {
 			Token:       "Middleware",
 		},
 		{
-			Constructor: repositories.NewMailerRepository,
-			Interface:   new(repositories.IMailerRepository),
-			Token:       "MailerRepository",
-		},
-		{
-			Constructor: repositories.NewUserRedisRepository,
-			Interface:   new(repositories.IUserRedisRepository),
-			Token:       "UserRedisRepository",
-		},
-		{
-			Constructor: repositories.NewUserRepository,
-			Interface:   new(repositories.IUserRepository),
-			Token:       "UserRepository",
+			Constructor: handlers.NewHealthHandler,
+			Interface:   new(handlers.IHealthHandler),
+			Token:       "HealthHandler",
 		},
 		{
-			Constructor: usecase.NewUserUsecase,
-			Interface:   new(usecase.IUserUsecase),
-			Token:       "UserUsecase",
+			Constructor: handlers.NewAdminHandler,
+			Interface:   new(handlers.IAdminHandler),
+			Token:       "AdminHandler",
 		},
 		{
-			Constructor: handlers.NewUserHandler,
-			Interface:   new(handlers.IUserHandler),
-			Token:       "UserHandler",
+			Constructor: app.NewApp,
 		},
 	}
-
-	for _, dep := range deps {
-		var err error
-		if dep.Interface != nil {
-			err = cn.container.Provide(dep.Constructor, dig.As(dep.Interface), dig.Name(dep.Token))
-		} else {
-			err = cn.container.Provide(dep.Constructor)
-		}
-		cn.Error = err
-	}
 }
@@ -0,0 +1,29 @@
+package container_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nutsp/golang-clean-architecture/internal/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The graph is resolved without calling constructors, so no database or Redis is needed.
+func TestContainerValidate(t *testing.T) {
+	cn := container.NewContainer()
+
+	require.NoError(t, cn.Error)
+	assert.NoError(t, cn.Validate())
+}
+
+func TestContainerPrintGraph(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, container.NewContainer().PrintGraph(&buf))
+
+	graph := buf.String()
+	assert.Contains(t, graph, "digraph {")
+	assert.Contains(t, graph, "*app.App")
+	assert.Contains(t, graph, "usecase.IUserUsecase[name=UserUsecase]")
+	assert.Contains(t, graph, "module.Routes[group=routes]")
+}
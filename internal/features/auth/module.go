@@ -0,0 +1,66 @@
+// Package auth wires the authentication feature: password reset and sessions.
+// It uses the user repositories of the users module.
+package auth
+
+import (
+	"github.com/nutsp/golang-clean-architecture/internal/handlers"
+	"github.com/nutsp/golang-clean-architecture/internal/middlewares"
+	"github.com/nutsp/golang-clean-architecture/internal/module"
+	"github.com/nutsp/golang-clean-architecture/internal/repositories"
+	"github.com/nutsp/golang-clean-architecture/internal/usecase"
+	"go.uber.org/dig"
+)
+
+var Module = module.Module{
+	Name: "auth",
+	Dependencies: []module.Dependency{
+		{
+			Constructor: repositories.NewPasswordResetRepository,
+			Interface:   new(repositories.IPasswordResetRepository),
+			Token:       "PasswordResetRepository",
+		},
+		{
+			Constructor: repositories.NewUserSessionRepository,
+			Interface:   new(repositories.IUserSessionRepository),
+			Token:       "UserSessionRepository",
+		},
+		{
+			Constructor: usecase.NewAuthUsecase,
+			Interface:   new(usecase.IAuthUsecase),
+			Token:       "AuthUsecase",
+		},
+		{
+			Constructor: handlers.NewAuthHandler,
+			Interface:   new(handlers.IAuthHandler),
+			Token:       "AuthHandler",
+		},
+	},
+	Routes:      routes,
+	Middlewares: moduleMiddlewares,
+}
+
+type routeDependencies struct {
+	dig.In
+	AuthHandler handlers.IAuthHandler `name:"AuthHandler"`
+}
+
+func routes(deps routeDependencies) module.Routes {
+	return func(r module.Router) {
+		auth := r.V1.Group("/auth")
+		auth.POST("/password/forgot", deps.AuthHandler.ForgotPasswordHandler)
+		auth.POST("/password/reset", deps.AuthHandler.ResetPasswordHandler)
+	}
+}
+
+type middlewareDependencies struct {
+	dig.In
+	Middleware            middlewares.IMiddleware             `name:"Middleware"`
+	UserSessionRepository repositories.IUserSessionRepository `name:"UserSessionRepository"`
+}
+
+func moduleMiddlewares(deps middlewareDependencies) []module.Middleware {
+	return []module.Middleware{
+		// Before the other module middlewares, so they never see a revoked user.
+		{Name: "session_revocation", Order: -1, Func: deps.Middleware.SessionRevocationMiddleware(deps.UserSessionRepository)},
+	}
+}
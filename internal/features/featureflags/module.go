@@ -0,0 +1,73 @@
+// Package featureflags wires the feature flags: the flag service, backed by the configuration
+// and optionally Redis, and the middleware setting the user they are evaluated for.
+package featureflags
+
+import (
+	"github.com/nutsp/golang-clean-architecture/config"
+	"github.com/nutsp/golang-clean-architecture/internal/middlewares"
+	"github.com/nutsp/golang-clean-architecture/internal/module"
+	"github.com/nutsp/golang-clean-architecture/pkg/datasource"
+	"github.com/nutsp/golang-clean-architecture/pkg/featureflag"
+	"github.com/nutsp/golang-clean-architecture/pkg/observability"
+	"go.uber.org/dig"
+)
+
+var Module = module.Module{
+	Name: "featureflags",
+	Dependencies: []module.Dependency{
+		{
+			Constructor: newService,
+			Interface:   new(featureflag.Flags),
+			Token:       "FeatureFlags",
+		},
+	},
+	Middlewares: moduleMiddlewares,
+}
+
+type serviceDependencies struct {
+	dig.In
+	Config      *config.Config
+	Reloader    *config.Reloader
+	Logger      observability.Logger    `name:"Logger"`
+	RedisClient datasource.IRedisClient `name:"RedisClient"`
+}
+
+func newService(deps serviceDependencies) *featureflag.Service {
+	cfg := deps.Config.Features
+
+	flags := featureflag.NewMemoryProvider(configFlags(cfg.Flags))
+	deps.Reloader.Subscribe("Features", func(cfg *config.Config) {
+		flags.Set(configFlags(cfg.Features.Flags))
+	})
+
+	var providers []featureflag.Provider
+	if cfg.Redis {
+		providers = append(providers, featureflag.NewRedisProvider(deps.Logger, deps.RedisClient, cfg.CacheTTL))
+	}
+	providers = append(providers, flags)
+
+	return featureflag.NewService(deps.Logger, providers...)
+}
+
+func configFlags(flags map[string]config.FeatureFlag) map[string]featureflag.Flag {
+	result := make(map[string]featureflag.Flag, len(flags))
+	for name, flag := range flags {
+		result[name] = featureflag.Flag{
+			Enable:     flag.Enable,
+			Users:      flag.Users,
+			Percentage: flag.Percentage,
+		}
+	}
+	return result
+}
+
+type middlewareDependencies struct {
+	dig.In
+	Middleware middlewares.IMiddleware `name:"Middleware"`
+}
+
+func moduleMiddlewares(deps middlewareDependencies) []module.Middleware {
+	return []module.Middleware{
+		{Name: "featureflag", Func: deps.Middleware.FeatureFlagMiddleware()},
+	}
+}
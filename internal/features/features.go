@@ -0,0 +1,20 @@
+// Package features lists the feature modules mounted by the application.
+package features
+
+import (
+	"github.com/nutsp/golang-clean-architecture/internal/features/auth"
+	"github.com/nutsp/golang-clean-architecture/internal/features/featureflags"
+	"github.com/nutsp/golang-clean-architecture/internal/features/mailer"
+	"github.com/nutsp/golang-clean-architecture/internal/features/users"
+	"github.com/nutsp/golang-clean-architecture/internal/module"
+)
+
+// Modules returns every feature module. A new feature only needs its package and a line here.
+func Modules() []module.Module {
+	return []module.Module{
+		featureflags.Module,
+		users.Module,
+		auth.Module,
+		mailer.Module,
+	}
+}
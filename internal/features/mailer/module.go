@@ -0,0 +1,59 @@
+// Package mailer wires the mailer API used to send emails, and its optional health check.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/nutsp/golang-clean-architecture/config"
+	"github.com/nutsp/golang-clean-architecture/internal/module"
+	"github.com/nutsp/golang-clean-architecture/internal/repositories"
+	"github.com/nutsp/golang-clean-architecture/pkg/health"
+	httpClient "github.com/nutsp/golang-clean-architecture/pkg/httpclient"
+	"go.uber.org/dig"
+)
+
+var Module = module.Module{
+	Name: "mailer",
+	Dependencies: []module.Dependency{
+		{
+			Constructor: repositories.NewMailerRepository,
+			Interface:   new(repositories.IMailerRepository),
+			Token:       "MailerRepository",
+		},
+		{
+			Constructor: healthDependencies.checkers,
+			Group:       "health,flatten",
+		},
+	},
+}
+
+type healthDependencies struct {
+	dig.In
+	Config *config.Config
+	Client httpClient.IClient `name:"MailerHttpClient"`
+}
+
+// checkers returns the optional check of the mailer API when it is enabled.
+func (deps healthDependencies) checkers() []health.Checker {
+	check := deps.Config.Health.Mailer
+	if !check.Enable {
+		return nil
+	}
+
+	return []health.Checker{{
+		Name:     "mailer",
+		Optional: true,
+		Check: func(ctx context.Context) error {
+			res, err := deps.Client.Do(ctx, &httpClient.Request{Method: httpClient.MethodGet, URL: check.Path})
+			if err != nil {
+				return err
+			}
+			if res.StatusCode >= http.StatusInternalServerError {
+				return fmt.Errorf("mailer answered %d", res.StatusCode)
+			}
+			return nil
+		},
+	}}
+}
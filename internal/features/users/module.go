@@ -0,0 +1,50 @@
+// Package users wires the user feature: its repositories, use case, handler and routes.
+package users
+
+import (
+	"github.com/nutsp/golang-clean-architecture/internal/handlers"
+	"github.com/nutsp/golang-clean-architecture/internal/module"
+	"github.com/nutsp/golang-clean-architecture/internal/repositories"
+	"github.com/nutsp/golang-clean-architecture/internal/usecase"
+	"go.uber.org/dig"
+)
+
+var Module = module.Module{
+	Name: "users",
+	Dependencies: []module.Dependency{
+		{
+			Constructor: repositories.NewUserRedisRepository,
+			Interface:   new(repositories.IUserRedisRepository),
+			Token:       "UserRedisRepository",
+		},
+		{
+			Constructor: repositories.NewUserRepository,
+			Interface:   new(repositories.IUserRepository),
+			Token:       "UserRepository",
+		},
+		{
+			Constructor: usecase.NewUserUsecase,
+			Interface:   new(usecase.IUserUsecase),
+			Token:       "UserUsecase",
+		},
+		{
+			Constructor: handlers.NewUserHandler,
+			Interface:   new(handlers.IUserHandler),
+			Token:       "UserHandler",
+		},
+	},
+	Routes: routes,
+}
+
+type routeDependencies struct {
+	dig.In
+	UserHandler handlers.IUserHandler `name:"UserHandler"`
+}
+
+func routes(deps routeDependencies) module.Routes {
+	return func(r module.Router) {
+		r.V1.POST("/users", deps.UserHandler.CreateUserHandler)
+		r.V1.PUT("/users", deps.UserHandler.UpdateUserHandler)
+		r.V1.GET("/users/:id", deps.UserHandler.GetUserInfoHandler)
+	}
+}
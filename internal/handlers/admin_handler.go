@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/nutsp/golang-clean-architecture/internal/models"
+	appError "github.com/nutsp/golang-clean-architecture/pkg/apperror"
+	"github.com/nutsp/golang-clean-architecture/pkg/observability"
+	"github.com/nutsp/golang-clean-architecture/pkg/response"
+	"go.uber.org/dig"
+)
+
+type IAdminHandler interface {
+	GetLogLevelHandler(c echo.Context) error
+	SetLogLevelHandler(c echo.Context) error
+}
+
+type AdminHandler struct {
+	logger   observability.Logger
+	logLevel observability.LevelController
+}
+
+type AdminHandlerDependencies struct {
+	dig.In
+	Logger   observability.Logger          `name:"Logger"`
+	LogLevel observability.LevelController `name:"LogLevel"`
+}
+
+func NewAdminHandler(deps AdminHandlerDependencies) *AdminHandler {
+	return &AdminHandler{
+		logger:   deps.Logger,
+		logLevel: deps.LogLevel,
+	}
+}
+
+func (h *AdminHandler) GetLogLevelHandler(c echo.Context) error {
+	return response.SuccessBuilder(models.LogLevel{Level: h.logLevel.Level()}).Send(c)
+}
+
+// SetLogLevelHandler changes the log level of the running process, until the next restart.
+func (h *AdminHandler) SetLogLevelHandler(c echo.Context) error {
+	req := new(models.LogLevel)
+	if err := c.Bind(req); err != nil {
+		return response.ErrorBuilder(appError.BadRequest(err)).Send(c)
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ErrorBuilder(appError.BadRequest(err)).Send(c)
+	}
+
+	previous := h.logLevel.Level()
+	if err := h.logLevel.SetLevel(req.Level); err != nil {
+		return response.ErrorBuilder(appError.BadRequest(err)).Send(c)
+	}
+	h.logger.WithContext(c.Request().Context()).Warn("SetLogLevelHandler", "previous", previous, "level", req.Level)
+
+	return response.SuccessBuilder(models.LogLevel{Level: h.logLevel.Level()}).Send(c)
+}
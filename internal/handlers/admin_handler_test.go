@@ -0,0 +1,89 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/nutsp/golang-clean-architecture/config"
+	"github.com/nutsp/golang-clean-architecture/internal/handlers"
+	"github.com/nutsp/golang-clean-architecture/pkg/observability"
+	"github.com/stretchr/testify/suite"
+)
+
+type AdminHandlerTestSuite struct {
+	suite.Suite
+	logger  *observability.ZapLogger
+	handler *handlers.AdminHandler
+}
+
+func (s *AdminHandlerTestSuite) SetupTest() {
+	s.logger = observability.NewZapLogger(config.Logger{Level: "info"})
+	s.handler = handlers.NewAdminHandler(handlers.AdminHandlerDependencies{
+		Logger:   s.logger,
+		LogLevel: s.logger,
+	})
+}
+
+func TestAdminHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(AdminHandlerTestSuite))
+}
+
+func (s *AdminHandlerTestSuite) TestGetLogLevelHandler() {
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/admin/log-level", nil), rec)
+
+	s.NoError(s.handler.GetLogLevelHandler(c))
+	s.Equal(http.StatusOK, rec.Code)
+	s.Contains(rec.Body.String(), `"level":"info"`)
+}
+
+func (s *AdminHandlerTestSuite) TestSetLogLevelHandler() {
+	e := echo.New()
+	e.Validator = &structValidator{validator: validator.New()}
+
+	tests := []struct {
+		name          string
+		requestBody   string
+		expectedCode  int
+		expectedLevel string
+	}{
+		{
+			name:          "success",
+			requestBody:   `{"level":"debug"}`,
+			expectedCode:  http.StatusOK,
+			expectedLevel: "debug",
+		},
+		{
+			name:          "bad_request_unknown_level",
+			requestBody:   `{"level":"verbose"}`,
+			expectedCode:  http.StatusBadRequest,
+			expectedLevel: "info",
+		},
+		{
+			name:          "bad_request_missing_level",
+			requestBody:   `{}`,
+			expectedCode:  http.StatusBadRequest,
+			expectedLevel: "info",
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			s.SetupTest()
+
+			req := httptest.NewRequest(http.MethodPut, "/admin/log-level", strings.NewReader(tt.requestBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			s.NoError(s.handler.SetLogLevelHandler(c))
+			s.Equal(tt.expectedCode, rec.Code)
+			s.Equal(tt.expectedLevel, s.logger.Level())
+		})
+	}
+}
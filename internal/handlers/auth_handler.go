@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/nutsp/golang-clean-architecture/internal/models"
+	"github.com/nutsp/golang-clean-architecture/internal/usecase"
+	appError "github.com/nutsp/golang-clean-architecture/pkg/apperror"
+	"github.com/nutsp/golang-clean-architecture/pkg/response"
+	"go.uber.org/dig"
+)
+
+type IAuthHandler interface {
+	ForgotPasswordHandler(c echo.Context) error
+	ResetPasswordHandler(c echo.Context) error
+}
+
+type AuthHandler struct {
+	authUsecase usecase.IAuthUsecase
+}
+
+type AuthHandlerDependencies struct {
+	dig.In
+	AuthUsecase usecase.IAuthUsecase `name:"AuthUsecase"`
+}
+
+func NewAuthHandler(deps AuthHandlerDependencies) *AuthHandler {
+	return &AuthHandler{
+		authUsecase: deps.AuthUsecase,
+	}
+}
+
+func (h *AuthHandler) ForgotPasswordHandler(c echo.Context) error {
+	req := new(models.ForgotPasswordRequest)
+	if err := c.Bind(req); err != nil {
+		return response.ErrorBuilder(appError.BadRequest(err)).Send(c)
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ErrorBuilder(appError.BadRequest(err)).Send(c)
+	}
+
+	if err := h.authUsecase.ForgotPassword(c.Request().Context(), req.Email); err != nil {
+		return response.ErrorBuilder(err).Send(c)
+	}
+
+	return response.SuccessBuilder(nil).Send(c)
+}
+
+func (h *AuthHandler) ResetPasswordHandler(c echo.Context) error {
+	req := new(models.ResetPasswordRequest)
+	if err := c.Bind(req); err != nil {
+		return response.ErrorBuilder(appError.BadRequest(err)).Send(c)
+	}
+
+	if err := c.Validate(req); err != nil {
+		return response.ErrorBuilder(appError.BadRequest(err)).Send(c)
+	}
+
+	if err := h.authUsecase.ResetPassword(c.Request().Context(), req.Token, req.Password); err != nil {
+		return response.ErrorBuilder(err).Send(c)
+	}
+
+	return response.SuccessBuilder(nil).Send(c)
+}
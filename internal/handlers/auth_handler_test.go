@@ -0,0 +1,137 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/nutsp/golang-clean-architecture/internal/handlers"
+	"github.com/nutsp/golang-clean-architecture/internal/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type structValidator struct {
+	validator *validator.Validate
+}
+
+func (v *structValidator) Validate(i interface{}) error {
+	return v.validator.Struct(i)
+}
+
+type AuthHandlerTestSuite struct {
+	suite.Suite
+	ctrl        *gomock.Controller
+	mockUsecase *mocks.MockIAuthUsecase
+	handler     *handlers.AuthHandler
+}
+
+func (s *AuthHandlerTestSuite) SetupTest() {
+	s.ctrl = gomock.NewController(s.T())
+	s.mockUsecase = mocks.NewMockIAuthUsecase(s.ctrl)
+
+	handlerDeps := handlers.AuthHandlerDependencies{
+		AuthUsecase: s.mockUsecase,
+	}
+
+	s.handler = handlers.NewAuthHandler(handlerDeps)
+}
+
+func (s *AuthHandlerTestSuite) TearDownTest() {
+	s.ctrl.Finish()
+}
+
+func TestAuthHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(AuthHandlerTestSuite))
+}
+
+func (s *AuthHandlerTestSuite) TestForgotPasswordHandler() {
+	e := echo.New()
+	e.Validator = &structValidator{validator: validator.New()}
+
+	tests := []struct {
+		name         string
+		requestBody  interface{}
+		expectedCode int
+		setupMocks   func()
+	}{
+		{
+			name:         "success",
+			requestBody:  map[string]string{"email": "john.doe@example.com"},
+			expectedCode: http.StatusOK,
+			setupMocks: func() {
+				s.mockUsecase.EXPECT().ForgotPassword(gomock.Any(), "john.doe@example.com").Return(nil)
+			},
+		},
+		{
+			name:         "bad_request_invalid_email",
+			requestBody:  map[string]string{"email": "not-an-email"},
+			expectedCode: http.StatusBadRequest,
+			setupMocks:   func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			tt.setupMocks()
+
+			body, _ := json.Marshal(tt.requestBody)
+			req := httptest.NewRequest(http.MethodPost, "/auth/password/forgot", bytes.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := s.handler.ForgotPasswordHandler(c)
+			assert.NoError(s.T(), err)
+			assert.Equal(s.T(), tt.expectedCode, rec.Code)
+		})
+	}
+}
+
+func (s *AuthHandlerTestSuite) TestResetPasswordHandler() {
+	e := echo.New()
+	e.Validator = &structValidator{validator: validator.New()}
+
+	tests := []struct {
+		name         string
+		requestBody  interface{}
+		expectedCode int
+		setupMocks   func()
+	}{
+		{
+			name:         "success",
+			requestBody:  map[string]string{"token": "token", "password": "new-password"},
+			expectedCode: http.StatusOK,
+			setupMocks: func() {
+				s.mockUsecase.EXPECT().ResetPassword(gomock.Any(), "token", "new-password").Return(nil)
+			},
+		},
+		{
+			name:         "bad_request_short_password",
+			requestBody:  map[string]string{"token": "token", "password": "short"},
+			expectedCode: http.StatusBadRequest,
+			setupMocks:   func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			tt.setupMocks()
+
+			body, _ := json.Marshal(tt.requestBody)
+			req := httptest.NewRequest(http.MethodPost, "/auth/password/reset", bytes.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := s.handler.ResetPasswordHandler(c)
+			assert.NoError(s.T(), err)
+			assert.Equal(s.T(), tt.expectedCode, rec.Code)
+		})
+	}
+}
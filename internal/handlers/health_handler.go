@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nutsp/golang-clean-architecture/pkg/health"
+	"github.com/nutsp/golang-clean-architecture/pkg/observability"
+	"go.uber.org/dig"
+)
+
+type IHealthHandler interface {
+	LivenessHandler(c echo.Context) error
+	ReadinessHandler(c echo.Context) error
+}
+
+type HealthHandler struct {
+	logger observability.Logger
+	health *health.Health
+}
+
+type HealthHandlerDependencies struct {
+	dig.In
+	Logger observability.Logger `name:"Logger"`
+	Health *health.Health
+}
+
+func NewHealthHandler(deps HealthHandlerDependencies) *HealthHandler {
+	return &HealthHandler{
+		logger: deps.Logger,
+		health: deps.Health,
+	}
+}
+
+// LivenessHandler answers 200 as long as the process serves requests.
+func (h *HealthHandler) LivenessHandler(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.health.Liveness())
+}
+
+// ReadinessHandler answers 503 when a required dependency is down or the server is shutting down.
+// The report only tells the status of every check, their errors are logged.
+func (h *HealthHandler) ReadinessHandler(c echo.Context) error {
+	report := h.health.Readiness(c.Request().Context())
+	for name, check := range report.Checks {
+		if check.Error != "" {
+			h.logger.WithContext(c.Request().Context()).Warn("ReadinessHandler", "check", name, "status", check.Status, "error", check.Error)
+		}
+	}
+
+	if !report.Up() {
+		return c.JSON(http.StatusServiceUnavailable, report)
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,81 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nutsp/golang-clean-architecture/config"
+	"github.com/nutsp/golang-clean-architecture/internal/handlers"
+	"github.com/nutsp/golang-clean-architecture/pkg/health"
+	"github.com/nutsp/golang-clean-architecture/pkg/observability"
+	"github.com/stretchr/testify/suite"
+)
+
+type HealthHandlerTestSuite struct {
+	suite.Suite
+	redisErr error
+	health   *health.Health
+	handler  *handlers.HealthHandler
+}
+
+func (s *HealthHandlerTestSuite) SetupTest() {
+	s.redisErr = nil
+	s.health = health.New(time.Second, time.Nanosecond, health.Checker{
+		Name:  "redis",
+		Check: func(context.Context) error { return s.redisErr },
+	})
+	s.handler = handlers.NewHealthHandler(handlers.HealthHandlerDependencies{
+		Logger: observability.NewZapLogger(config.Logger{Level: "error"}),
+		Health: s.health,
+	})
+}
+
+func TestHealthHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(HealthHandlerTestSuite))
+}
+
+func (s *HealthHandlerTestSuite) serve(handler echo.HandlerFunc) *httptest.ResponseRecorder {
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+
+	s.Require().NoError(handler(c))
+	return rec
+}
+
+func (s *HealthHandlerTestSuite) TestLivenessHandler() {
+	s.redisErr = errors.New("connection refused")
+
+	rec := s.serve(s.handler.LivenessHandler)
+
+	var report health.Report
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &report))
+	s.Equal(http.StatusOK, rec.Code)
+	s.Equal(health.StatusUp, report.Status)
+}
+
+func (s *HealthHandlerTestSuite) TestReadinessHandler() {
+	rec := s.serve(s.handler.ReadinessHandler)
+	s.Equal(http.StatusOK, rec.Code)
+
+	// Only the status of the check is served, its error may disclose the dependency.
+	s.redisErr = errors.New("dial tcp 10.0.0.7:6379: connection refused")
+	rec = s.serve(s.handler.ReadinessHandler)
+	s.Equal(http.StatusServiceUnavailable, rec.Code)
+	var report health.Report
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &report))
+	s.Equal(health.StatusDown, report.Checks["redis"].Status)
+	s.NotContains(rec.Body.String(), "10.0.0.7")
+
+	s.redisErr = nil
+	s.health.SetShuttingDown()
+	rec = s.serve(s.handler.ReadinessHandler)
+	s.Equal(http.StatusServiceUnavailable, rec.Code)
+	s.Contains(rec.Body.String(), `"shutdown":{"status":"down"`)
+}
@@ -1,6 +1,10 @@
 package database
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
+
 	"github.com/nutsp/golang-clean-architecture/config"
 	"github.com/nutsp/golang-clean-architecture/pkg/datasource"
 	"github.com/samber/lo"
@@ -10,6 +14,10 @@ import (
 type IDatabase interface {
 	OllamaDB() datasource.DB
 	GptDB() datasource.DB
+	// Pools returns the connection pool of every database, keyed by database name.
+	Pools() (map[string]*sql.DB, error)
+	// Close closes the connection pool of every database.
+	Close() error
 }
 
 type Database struct {
@@ -25,9 +33,41 @@ func NewDatabase(cfg *config.Config) *Database {
 }
 
 func (db *Database) OllamaDB() datasource.DB {
-	return &datasource.GormDB{db.ollamadb}
+	return &datasource.GormDB{DB: db.ollamadb}
 }
 
 func (db *Database) GptDB() datasource.DB {
-	return &datasource.GormDB{db.gptdb}
+	return &datasource.GormDB{DB: db.gptdb}
+}
+
+func (db *Database) Pools() (map[string]*sql.DB, error) {
+	ollama, err := db.ollamadb.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	gpt, err := db.gptdb.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]*sql.DB{
+		"ollama": ollama,
+		"gpt":    gpt,
+	}, nil
+}
+
+func (db *Database) Close() error {
+	pools, err := db.Pools()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for name, pool := range pools {
+		if err := pool.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
 }
@@ -5,6 +5,7 @@
 package mock_database
 
 import (
+	sql "database/sql"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -34,6 +35,20 @@ func (m *MockIDatabase) EXPECT() *MockIDatabaseMockRecorder {
 	return m.recorder
 }
 
+// Close mocks base method.
+func (m *MockIDatabase) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockIDatabaseMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockIDatabase)(nil).Close))
+}
+
 // GptDB mocks base method.
 func (m *MockIDatabase) GptDB() datasource.DB {
 	m.ctrl.T.Helper()
@@ -61,3 +76,18 @@ func (mr *MockIDatabaseMockRecorder) OllamaDB() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OllamaDB", reflect.TypeOf((*MockIDatabase)(nil).OllamaDB))
 }
+
+// Pools mocks base method.
+func (m *MockIDatabase) Pools() (map[string]*sql.DB, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pools")
+	ret0, _ := ret[0].(map[string]*sql.DB)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Pools indicates an expected call of Pools.
+func (mr *MockIDatabaseMockRecorder) Pools() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pools", reflect.TypeOf((*MockIDatabase)(nil).Pools))
+}
@@ -0,0 +1,48 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"errors"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	appError "github.com/nutsp/golang-clean-architecture/pkg/apperror"
+)
+
+const bearerPrefix = "Bearer "
+
+var (
+	errInvalidAdminToken   = errors.New("invalid admin token")
+	errInvalidMetricsToken = errors.New("invalid metrics token")
+)
+
+// AdminMiddleware lets through the requests carrying the admin token as a bearer token.
+// Every request is rejected when no token is configured.
+func (mw *Middleware) AdminMiddleware() echo.MiddlewareFunc {
+	return bearerTokenMiddleware([]byte(mw.config.Admin.Token.Value()), errInvalidAdminToken)
+}
+
+// MetricsAccessMiddleware lets through the scrapes carrying the metrics token as a bearer token.
+// The token only grants reading the metrics, scrapers never hold the admin token.
+// Every scrape is let through when no token is configured.
+func (mw *Middleware) MetricsAccessMiddleware() echo.MiddlewareFunc {
+	token := []byte(mw.config.Metrics.Token.Value())
+	if len(token) == 0 {
+		return func(next echo.HandlerFunc) echo.HandlerFunc { return next }
+	}
+	return bearerTokenMiddleware(token, errInvalidMetricsToken)
+}
+
+func bearerTokenMiddleware(token []byte, invalid error) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			authorization := c.Request().Header.Get(echo.HeaderAuthorization)
+			if len(token) == 0 || !strings.HasPrefix(authorization, bearerPrefix) ||
+				subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(authorization, bearerPrefix)), token) != 1 {
+				return appError.Unauthorized(invalid)
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nutsp/golang-clean-architecture/config"
+	"github.com/nutsp/golang-clean-architecture/internal/middlewares"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminMiddleware(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		expectedCode  int
+	}{
+		{name: "accepts the admin token", token: "s3cret", authorization: "Bearer s3cret", expectedCode: http.StatusOK},
+		{name: "rejects another token", token: "s3cret", authorization: "Bearer s3cre", expectedCode: http.StatusUnauthorized},
+		{name: "rejects a missing token", token: "s3cret", expectedCode: http.StatusUnauthorized},
+		{name: "rejects every request without a configured token", authorization: "Bearer ", expectedCode: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mw := newTestMiddleware(middlewares.MiddlewareDependencies{
+				Config: &config.Config{Admin: config.AdminConfig{Token: config.Secret(tt.token)}},
+			})
+
+			e := newTestServer()
+			e.GET("/admin/log-level", func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			}, mw.AdminMiddleware())
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/log-level", nil)
+			if tt.authorization != "" {
+				req.Header.Set(echo.HeaderAuthorization, tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+		})
+	}
+}
+
+func TestMetricsAccessMiddleware(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		expectedCode  int
+	}{
+		{name: "accepts the metrics token", token: "scrape", authorization: "Bearer scrape", expectedCode: http.StatusOK},
+		{name: "rejects the admin token", token: "scrape", authorization: "Bearer s3cret", expectedCode: http.StatusUnauthorized},
+		{name: "rejects a missing token", token: "scrape", expectedCode: http.StatusUnauthorized},
+		{name: "serves every scrape without a configured token", expectedCode: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mw := newTestMiddleware(middlewares.MiddlewareDependencies{
+				Config: &config.Config{
+					Admin:   config.AdminConfig{Token: "s3cret"},
+					Metrics: config.MetricsConfig{Token: config.Secret(tt.token)},
+				},
+			})
+
+			e := newTestServer()
+			// The scrape token is not a JWT, AuthenticationMiddleware leaves it to MetricsAccessMiddleware.
+			e.Use(mw.AuthenticationMiddleware())
+			e.GET("/metrics", func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			}, mw.MetricsAccessMiddleware())
+
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tt.authorization != "" {
+				req.Header.Set(echo.HeaderAuthorization, tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+		})
+	}
+}
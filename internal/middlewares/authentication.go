@@ -0,0 +1,106 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	appError "github.com/nutsp/golang-clean-architecture/pkg/apperror"
+)
+
+const (
+	// adminPathPrefix is where the routes authenticated by AdminMiddleware live.
+	adminPathPrefix = "/admin/"
+	// metricsPath is the route authenticated by MetricsAccessMiddleware.
+	metricsPath = "/metrics"
+)
+
+var (
+	errInvalidToken = errors.New("invalid token")
+	errTokenRevoked = errors.New("token revoked")
+)
+
+// contextKeyTokenIssuedAt is the echo context key under which AuthenticationMiddleware stores
+// when the token of the request was issued.
+const contextKeyTokenIssuedAt = "token_issued_at"
+
+// SessionRevocations tells when the sessions of a user were last revoked.
+type SessionRevocations interface {
+	// RevokedAt returns the zero time when the sessions of the user were never revoked.
+	RevokedAt(ctx context.Context, userID uint) (time.Time, error)
+}
+
+// AuthenticationMiddleware identifies the user of a request carrying a bearer JWT signed with
+// JWT.Key, and stores their ID under ContextKeyUserID for the rate limiter, the feature flags
+// and the handlers. The token must carry its subject, issue and expiry times, and live no
+// longer than JWT.Expired. Requests without a token go through anonymously. Revoked sessions
+// are rejected by SessionRevocationMiddleware, contributed by the auth module.
+func (mw *Middleware) AuthenticationMiddleware() echo.MiddlewareFunc {
+	key := []byte(mw.config.JWT.Key.Value())
+	lifetime := int64(mw.config.JWT.Expired)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			authorization := c.Request().Header.Get(echo.HeaderAuthorization)
+			// The admin routes and the metrics carry their own token instead.
+			if authorization == "" || strings.HasPrefix(c.Path(), adminPathPrefix) || c.Path() == metricsPath {
+				return next(c)
+			}
+			if !strings.HasPrefix(authorization, bearerPrefix) {
+				return appError.Unauthorized(errInvalidToken)
+			}
+
+			claims := &jwt.StandardClaims{}
+			_, err := jwt.ParseWithClaims(strings.TrimPrefix(authorization, bearerPrefix), claims, func(token *jwt.Token) (interface{}, error) {
+				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+					return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+				}
+				return key, nil
+			})
+			if err != nil || claims.IssuedAt == 0 || claims.ExpiresAt == 0 || claims.ExpiresAt-claims.IssuedAt > lifetime {
+				return appError.Unauthorized(errInvalidToken)
+			}
+			userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+			if err != nil || userID == 0 {
+				return appError.Unauthorized(errInvalidToken)
+			}
+
+			c.Set(ContextKeyUserID, uint(userID))
+			c.Set(contextKeyTokenIssuedAt, time.Unix(claims.IssuedAt, 0))
+
+			return next(c)
+		}
+	}
+}
+
+// SessionRevocationMiddleware rejects the tokens issued before the second the sessions of their
+// user were revoked in, e.g. by a password reset. It must run after AuthenticationMiddleware.
+func (mw *Middleware) SessionRevocationMiddleware(sessions SessionRevocations) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			userID, ok := c.Get(ContextKeyUserID).(uint)
+			if !ok {
+				return next(c)
+			}
+			issuedAt, _ := c.Get(contextKeyTokenIssuedAt).(time.Time)
+
+			revokedAt, err := sessions.RevokedAt(c.Request().Context(), userID)
+			if err != nil {
+				// Without the revocations, a stolen session could not be told apart.
+				return appError.InternalServerError(err)
+			}
+			// Tokens carry whole seconds: a login in the same second as the revocation, right
+			// after a password reset, is let through.
+			if !revokedAt.IsZero() && issuedAt.Before(revokedAt.Truncate(time.Second)) {
+				return appError.Unauthorized(errTokenRevoked)
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package middlewares_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/nutsp/golang-clean-architecture/config"
+	"github.com/nutsp/golang-clean-architecture/internal/middlewares"
+	"github.com/nutsp/golang-clean-architecture/internal/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const jwtKey = "jwt-key"
+
+func signToken(t *testing.T, key string, claims jwt.StandardClaims) http.Header {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(key))
+	require.NoError(t, err)
+	return http.Header{echo.HeaderAuthorization: {"Bearer " + token}}
+}
+
+func TestAuthenticationMiddleware(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	sessions := mocks.NewMockIUserSessionRepository(ctrl)
+
+	mw := newTestMiddleware(middlewares.MiddlewareDependencies{
+		Config: &config.Config{JWT: config.JWTConfig{Key: jwtKey, Expired: 3600}},
+	})
+
+	e := newTestServer()
+	e.Use(mw.AuthenticationMiddleware())
+	e.Use(mw.SessionRevocationMiddleware(sessions))
+	whoami := func(c echo.Context) error {
+		return c.String(http.StatusOK, fmt.Sprint(c.Get(middlewares.ContextKeyUserID)))
+	}
+	e.GET("/api/v1/me", whoami)
+	e.GET("/admin/log-level", whoami)
+
+	now := time.Now()
+	valid := jwt.StandardClaims{Subject: "42", IssuedAt: now.Add(-time.Minute).Unix(), ExpiresAt: now.Add(time.Minute).Unix()}
+
+	t.Run("anonymous request", func(t *testing.T) {
+		rec := serve(e, http.MethodGet, "/api/v1/me", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "<nil>", rec.Body.String())
+	})
+
+	t.Run("valid token", func(t *testing.T) {
+		sessions.EXPECT().RevokedAt(gomock.Any(), uint(42)).Return(time.Time{}, nil)
+
+		rec := serve(e, http.MethodGet, "/api/v1/me", signToken(t, jwtKey, valid))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "42", rec.Body.String())
+	})
+
+	t.Run("token issued before the sessions were revoked", func(t *testing.T) {
+		sessions.EXPECT().RevokedAt(gomock.Any(), uint(42)).Return(now.Add(-30*time.Second), nil)
+		rec := serve(e, http.MethodGet, "/api/v1/me", signToken(t, jwtKey, valid))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+		// A token issued after the revocation, e.g. on the next login, is accepted.
+		sessions.EXPECT().RevokedAt(gomock.Any(), uint(42)).Return(now.Add(-2*time.Minute), nil)
+		rec = serve(e, http.MethodGet, "/api/v1/me", signToken(t, jwtKey, valid))
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		// Even in the same second as the revocation.
+		sessions.EXPECT().RevokedAt(gomock.Any(), uint(42)).Return(time.Unix(valid.IssuedAt, 999_000_000), nil)
+		rec = serve(e, http.MethodGet, "/api/v1/me", signToken(t, jwtKey, valid))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("revocations unavailable", func(t *testing.T) {
+		sessions.EXPECT().RevokedAt(gomock.Any(), uint(42)).Return(time.Time{}, errors.New("connection refused"))
+
+		rec := serve(e, http.MethodGet, "/api/v1/me", signToken(t, jwtKey, valid))
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+
+	invalid := []struct {
+		name   string
+		header http.Header
+	}{
+		{name: "wrong key", header: signToken(t, "other-key", valid)},
+		{name: "expired", header: signToken(t, jwtKey, jwt.StandardClaims{Subject: "42", IssuedAt: now.Add(-time.Hour).Unix(), ExpiresAt: now.Add(-time.Minute).Unix()})},
+		{name: "no expiry", header: signToken(t, jwtKey, jwt.StandardClaims{Subject: "42", IssuedAt: valid.IssuedAt})},
+		{name: "longer than JWT.Expired", header: signToken(t, jwtKey, jwt.StandardClaims{Subject: "42", IssuedAt: valid.IssuedAt, ExpiresAt: now.Add(2 * time.Hour).Unix()})},
+		{name: "no subject", header: signToken(t, jwtKey, jwt.StandardClaims{IssuedAt: valid.IssuedAt, ExpiresAt: valid.ExpiresAt})},
+		{name: "not a bearer token", header: http.Header{echo.HeaderAuthorization: {"Basic YTpi"}}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(e, http.MethodGet, "/api/v1/me", tt.header)
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		})
+	}
+
+	t.Run("admin routes carry the admin token", func(t *testing.T) {
+		rec := serve(e, http.MethodGet, "/admin/log-level", http.Header{echo.HeaderAuthorization: {"Bearer admin-token"}})
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
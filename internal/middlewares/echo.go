@@ -1,6 +1,7 @@
 package middlewares
 
 import (
+	"net"
 	"net/http"
 
 	"github.com/go-playground/validator/v10"
@@ -17,21 +18,85 @@ func (v *CustomValidator) Validate(i interface{}) error {
 	return v.validator.Struct(i)
 }
 
-var configCors = echoMiddleware.CORSConfig{
-	AllowOrigins: []string{"*"},
-	AllowMethods: []string{http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete, http.MethodPatch},
-}
+// corsMethods are the methods allowed to cross-origin requests.
+var corsMethods = []string{http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete, http.MethodPatch}
 
 func NewEchoServer(cfg *config.Config, mw IMiddleware) *echo.Echo {
 	e := echo.New()
+	e.IPExtractor = ipExtractor(cfg.Server.TrustedProxies)
 
-	e.Use(echoMiddleware.CORSWithConfig(configCors))
+	e.Use(mw.RequestIDMiddleware())
+	e.Use(mw.MetricsMiddleware())
+	e.Use(mw.TracingMiddleware())
+	e.Use(echoMiddleware.SecureWithConfig(secureConfig(cfg.Server.Headers)))
+	// Without origins, browsers only allow requests from the origin of the API itself.
+	if len(cfg.Server.CORS.AllowOrigins) > 0 {
+		e.Use(echoMiddleware.CORSWithConfig(corsConfig(cfg.Server.CORS)))
+	}
 	e.Use(mw.LoggingMiddleware())
+	// Inside the logging middleware, so a panic is logged as a 500 request.
+	e.Use(mw.RecoverMiddleware())
+	if cfg.Server.BodyLimit != "" {
+		e.Use(echoMiddleware.BodyLimit(cfg.Server.BodyLimit))
+	}
+	// Before the rate limiter, which can count requests by user.
+	e.Use(mw.AuthenticationMiddleware())
+	e.Use(mw.RateLimitMiddleware())
+	e.Use(mw.IdempotencyMiddleware())
 
 	e.Validator = &CustomValidator{validator: validator.New()}
 	e.HTTPErrorHandler = errorHandler
 	e.Debug = cfg.Server.Debug
 	e.HideBanner = true
 
+	e.Server.ReadTimeout = cfg.Server.ReadTimeout
+	e.Server.ReadHeaderTimeout = cfg.Server.ReadHeaderTimeout
+	e.Server.WriteTimeout = cfg.Server.WriteTimeout
+	e.Server.IdleTimeout = cfg.Server.IdleTimeout
+
 	return e
 }
+
+// ipExtractor returns how the client IP is found, used by the rate limiter, the feature
+// flags and the logs. Headers sent by the client itself can not be trusted, so
+// X-Forwarded-For is only read behind the trusted proxies.
+func ipExtractor(trustedProxies []string) echo.IPExtractor {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, cidr := range trustedProxies {
+		// Validated by the configuration.
+		_, ipNet, _ := net.ParseCIDR(cidr)
+		options = append(options, echo.TrustIPRange(ipNet))
+	}
+	return echo.ExtractIPFromXFFHeader(options...)
+}
+
+func corsConfig(cfg config.ServerCORS) echoMiddleware.CORSConfig {
+	return echoMiddleware.CORSConfig{
+		AllowOrigins:     cfg.AllowOrigins,
+		AllowMethods:     corsMethods,
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           int(cfg.MaxAge.Seconds()),
+	}
+}
+
+func secureConfig(cfg config.ServerHeaders) echoMiddleware.SecureConfig {
+	return echoMiddleware.SecureConfig{
+		// Browsers dropped their XSS auditor, which could itself be abused, so it is turned off.
+		XSSProtection:         "0",
+		ContentTypeNosniff:    "nosniff",
+		XFrameOptions:         cfg.FrameOptions,
+		HSTSMaxAge:            int(cfg.HSTSMaxAge.Seconds()),
+		HSTSExcludeSubdomains: !cfg.HSTSIncludeSubdomains,
+		HSTSPreloadEnabled:    cfg.HSTSPreload,
+		ContentSecurityPolicy: cfg.ContentSecurityPolicy,
+		ReferrerPolicy:        cfg.ReferrerPolicy,
+	}
+}
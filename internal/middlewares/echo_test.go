@@ -0,0 +1,167 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/nutsp/golang-clean-architecture/config"
+	"github.com/nutsp/golang-clean-architecture/internal/middlewares"
+	"github.com/nutsp/golang-clean-architecture/pkg/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func newHardenedServer(t *testing.T) *echo.Echo {
+	t.Helper()
+
+	cfg := &config.Config{Server: config.ServerConfig{
+		ReadTimeout:  time.Second,
+		WriteTimeout: 2 * time.Second,
+		IdleTimeout:  time.Minute,
+		BodyLimit:    "16B",
+		CORS: config.ServerCORS{
+			AllowOrigins:     []string{"https://app.example.com"},
+			AllowCredentials: true,
+		},
+		Headers: config.ServerHeaders{
+			HSTSMaxAge:            time.Hour,
+			ContentSecurityPolicy: "default-src 'none'",
+			FrameOptions:          "DENY",
+		},
+	}}
+	mw := newTestMiddleware(middlewares.MiddlewareDependencies{
+		Config:  cfg,
+		Metrics: metrics.NewHTTPMetrics(),
+	})
+
+	e := middlewares.NewEchoServer(cfg, mw)
+	e.POST("/users", func(c echo.Context) error {
+		var body map[string]interface{}
+		if err := c.Bind(&body); err != nil {
+			return err
+		}
+		return c.NoContent(http.StatusCreated)
+	})
+	e.GET("/panic", func(c echo.Context) error { panic("boom") })
+
+	return e
+}
+
+func TestEchoServerTimeouts(t *testing.T) {
+	e := newHardenedServer(t)
+
+	assert.Equal(t, time.Second, e.Server.ReadTimeout)
+	assert.Equal(t, 2*time.Second, e.Server.WriteTimeout)
+	assert.Equal(t, time.Minute, e.Server.IdleTimeout)
+}
+
+func TestEchoServerSecurityHeaders(t *testing.T) {
+	e := newHardenedServer(t)
+
+	rec := serve(e, http.MethodGet, "/panic", nil)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "nosniff", rec.Header().Get(echo.HeaderXContentTypeOptions))
+	assert.Equal(t, "DENY", rec.Header().Get(echo.HeaderXFrameOptions))
+	assert.Equal(t, "default-src 'none'", rec.Header().Get(echo.HeaderContentSecurityPolicy))
+	// HSTS is only sent over HTTPS.
+	assert.Empty(t, rec.Header().Get(echo.HeaderStrictTransportSecurity))
+
+	rec = serve(e, http.MethodGet, "/panic", http.Header{echo.HeaderXForwardedProto: {"https"}})
+	assert.Equal(t, "max-age=3600", rec.Header().Get(echo.HeaderStrictTransportSecurity))
+}
+
+func TestEchoServerCORS(t *testing.T) {
+	e := newHardenedServer(t)
+
+	preflight := func(origin string) http.Header {
+		return serve(e, http.MethodOptions, "/users", http.Header{
+			echo.HeaderOrigin:                     {origin},
+			echo.HeaderAccessControlRequestMethod: {http.MethodPost},
+		}).Header()
+	}
+
+	header := preflight("https://app.example.com")
+	assert.Equal(t, "https://app.example.com", header.Get(echo.HeaderAccessControlAllowOrigin))
+	assert.Equal(t, "true", header.Get(echo.HeaderAccessControlAllowCredentials))
+
+	assert.Empty(t, preflight("https://evil.example.com").Get(echo.HeaderAccessControlAllowOrigin))
+}
+
+func TestEchoServerBodyLimit(t *testing.T) {
+	e := newHardenedServer(t)
+
+	post := func(body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusCreated, post(`{"name":"john"}`))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, post(`{"name":"john doe"}`))
+}
+
+func newClientKeyedServer(t *testing.T, trustedProxies []string, keyBy string) *echo.Echo {
+	t.Helper()
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{TrustedProxies: trustedProxies},
+		JWT:    config.JWTConfig{Key: jwtKey, Expired: 3600},
+		RateLimit: config.RateLimitConfig{
+			Enable: true,
+			Limit:  1,
+			Window: time.Minute,
+			KeyBy:  keyBy,
+		},
+	}
+	mw := newTestMiddleware(middlewares.MiddlewareDependencies{
+		Config:  cfg,
+		Metrics: metrics.NewHTTPMetrics(),
+	})
+
+	e := middlewares.NewEchoServer(cfg, mw)
+	e.GET("/api/v1/me", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+	return e
+}
+
+func TestEchoServerClientIP(t *testing.T) {
+	// httptest requests come from 192.0.2.1.
+	spoofed := http.Header{
+		echo.HeaderXForwardedFor: {"198.51.100.7"},
+		echo.HeaderXRealIP:       {"198.51.100.7"},
+	}
+
+	t.Run("forwarding headers ignored without trusted proxies", func(t *testing.T) {
+		e := newClientKeyedServer(t, nil, "")
+
+		assert.Equal(t, http.StatusOK, serve(e, http.MethodGet, "/api/v1/me", nil).Code)
+		assert.Equal(t, http.StatusTooManyRequests, serve(e, http.MethodGet, "/api/v1/me", spoofed).Code)
+	})
+
+	t.Run("forwarding headers read behind a trusted proxy", func(t *testing.T) {
+		e := newClientKeyedServer(t, []string{"192.0.2.0/24"}, "")
+
+		assert.Equal(t, http.StatusOK, serve(e, http.MethodGet, "/api/v1/me", spoofed).Code)
+		assert.Equal(t, http.StatusTooManyRequests, serve(e, http.MethodGet, "/api/v1/me", spoofed).Code)
+		other := http.Header{echo.HeaderXForwardedFor: {"198.51.100.8"}}
+		assert.Equal(t, http.StatusOK, serve(e, http.MethodGet, "/api/v1/me", other).Code)
+	})
+
+	t.Run("authenticated users counted apart", func(t *testing.T) {
+		e := newClientKeyedServer(t, nil, middlewares.RateLimitKeyByUser)
+		now := time.Now()
+		token := func(subject string) http.Header {
+			return signToken(t, jwtKey, jwt.StandardClaims{Subject: subject, IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Minute).Unix()})
+		}
+
+		assert.Equal(t, http.StatusOK, serve(e, http.MethodGet, "/api/v1/me", token("1")).Code)
+		assert.Equal(t, http.StatusOK, serve(e, http.MethodGet, "/api/v1/me", token("2")).Code)
+		assert.Equal(t, http.StatusTooManyRequests, serve(e, http.MethodGet, "/api/v1/me", token("1")).Code)
+	})
+}
@@ -43,6 +43,10 @@ func errorHandler(err error, c echo.Context) {
 		case http.StatusNotFound:
 			response.ErrorBuilder(appError.NotFound(errors.New("route not found"))).Send(c)
 
+			return
+		case http.StatusRequestEntityTooLarge:
+			response.ErrorBuilder(appError.RequestEntityTooLarge(appError.ErrBodyTooLarge)).Send(c)
+
 			return
 		default:
 			response.ErrorBuilder(err).Send(c)
@@ -127,3 +131,22 @@ func errorHandler(err error, c echo.Context) {
 
 	response.ErrorBuilder(err).Send(c)
 }
+
+// contextKeyHandledError is the echo context key of the error already sent by handleError.
+const contextKeyHandledError = "handled_error"
+
+// handleError sends err through the error handler right away, so the calling middleware
+// sees the final status, and keeps err for the middlewares further out.
+func handleError(c echo.Context, err error) {
+	c.Set(contextKeyHandledError, err)
+	c.Error(err)
+}
+
+// requestError returns err, or the error an inner middleware already handled when err is nil.
+func requestError(c echo.Context, err error) error {
+	if err != nil {
+		return err
+	}
+	handled, _ := c.Get(contextKeyHandledError).(error)
+	return handled
+}
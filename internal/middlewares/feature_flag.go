@@ -0,0 +1,41 @@
+package middlewares
+
+import (
+	"fmt"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nutsp/golang-clean-architecture/pkg/featureflag"
+)
+
+// FeatureFlagMiddleware sets the user the feature flags are evaluated for in the request
+// context: the user set by AuthenticationMiddleware, or the client IP for anonymous requests,
+// so a percentage rollout also covers them. It must run after AuthenticationMiddleware.
+func (mw *Middleware) FeatureFlagMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user := "ip:" + c.RealIP()
+			if userID := c.Get(ContextKeyUserID); userID != nil {
+				user = fmt.Sprint(userID)
+			}
+
+			req := c.Request()
+			c.SetRequest(req.WithContext(featureflag.WithUser(req.Context(), user)))
+
+			return next(c)
+		}
+	}
+}
+
+// RequireFeatureMiddleware answers 404, as for a route that does not exist, unless the
+// feature name is on for the user, so a route can be launched dark.
+func (mw *Middleware) RequireFeatureMiddleware(name string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if mw.features == nil || !mw.features.Enabled(c.Request().Context(), name) {
+				return echo.ErrNotFound
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/nutsp/golang-clean-architecture/config"
+	"github.com/nutsp/golang-clean-architecture/internal/middlewares"
+	"github.com/nutsp/golang-clean-architecture/pkg/featureflag"
+	"github.com/nutsp/golang-clean-architecture/pkg/observability"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeatureFlagMiddleware(t *testing.T) {
+	logger := observability.NewZapLogger(config.Logger{})
+	flags := featureflag.NewMemoryProvider(map[string]featureflag.Flag{
+		"new-search": {Users: []string{"42", "ip:192.0.2.1"}},
+	})
+	cfg := &config.Config{JWT: config.JWTConfig{Key: jwtKey, Expired: 3600}}
+	mw := newTestMiddleware(middlewares.MiddlewareDependencies{
+		Config:   cfg,
+		Logger:   logger,
+		Features: featureflag.NewService(logger, flags),
+	})
+
+	e := middlewares.NewEchoServer(cfg, mw)
+	e.Use(mw.FeatureFlagMiddleware())
+	e.GET("/search", func(c echo.Context) error {
+		return c.String(http.StatusOK, featureflag.UserFromContext(c.Request().Context()))
+	}, mw.RequireFeatureMiddleware("new-search"))
+
+	now := time.Now()
+	token := func(subject string) http.Header {
+		return signToken(t, jwtKey, jwt.StandardClaims{Subject: subject, IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Minute).Unix()})
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		header     http.Header
+		status     int
+	}{
+		{name: "authenticated user in the rollout", header: token("42"), status: http.StatusOK},
+		{name: "authenticated user out of the rollout", header: token("7"), status: http.StatusNotFound},
+		{name: "anonymous client in the rollout", remoteAddr: "192.0.2.1:1234", status: http.StatusOK},
+		{name: "anonymous client out of the rollout", remoteAddr: "192.0.2.2:1234", status: http.StatusNotFound},
+		{name: "anonymous client spoofing its IP", remoteAddr: "192.0.2.2:1234", header: http.Header{echo.HeaderXRealIP: {"192.0.2.1"}}, status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/search", nil)
+			if tt.remoteAddr != "" {
+				req.RemoteAddr = tt.remoteAddr
+			}
+			for k, values := range tt.header {
+				req.Header[k] = values
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package middlewares_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nutsp/golang-clean-architecture/config"
+	"github.com/nutsp/golang-clean-architecture/internal/middlewares"
+	appError "github.com/nutsp/golang-clean-architecture/pkg/apperror"
+	"github.com/nutsp/golang-clean-architecture/pkg/datasource"
+	"github.com/nutsp/golang-clean-architecture/pkg/observability"
+	"github.com/nutsp/golang-clean-architecture/pkg/response"
+)
+
+// newTestMiddleware builds the middlewares from deps, with an empty config, a logger writing
+// only errors and an in-memory Redis in place of the dependencies left unset.
+func newTestMiddleware(deps middlewares.MiddlewareDependencies) *middlewares.Middleware {
+	if deps.Config == nil {
+		deps.Config = &config.Config{}
+	}
+	if deps.Logger == nil {
+		deps.Logger = observability.NewZapLogger(config.Logger{Level: "error"})
+	}
+	if deps.RedisClient == nil {
+		deps.RedisClient = datasource.NewMemoryRedisClient()
+	}
+
+	return middlewares.NewMiddleware(deps)
+}
+
+// newTestServer returns an echo answering an *AppError the way the API does, any other error
+// the echo way.
+func newTestServer() *echo.Echo {
+	e := echo.New()
+	e.HTTPErrorHandler = func(err error, c echo.Context) {
+		var appErr *appError.AppError
+		if errors.As(err, &appErr) {
+			response.ErrorBuilder(err).Send(c)
+			return
+		}
+		e.DefaultHTTPErrorHandler(err, c)
+	}
+
+	return e
+}
+
+// serve sends a request without body to e and returns the recorded response.
+func serve(e *echo.Echo, method, path string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	for k, values := range header {
+		for _, v := range values {
+			req.Header.Add(k, v)
+		}
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
@@ -0,0 +1,264 @@
+package middlewares
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/labstack/echo/v4"
+	appError "github.com/nutsp/golang-clean-architecture/pkg/apperror"
+	"github.com/nutsp/golang-clean-architecture/pkg/datasource"
+	"github.com/nutsp/golang-clean-architecture/pkg/response"
+)
+
+const (
+	HeaderIdempotencyKey      = "Idempotency-Key"
+	HeaderIdempotentReplayed  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	defaultIdempotencyTTL     = 24 * time.Hour
+	defaultIdempotencyLockTTL = time.Minute
+	defaultIdempotencyBody    = 1 << 20
+)
+
+// idempotentResponse is the first response sent for an idempotency key, as stored in Redis.
+type idempotentResponse struct {
+	Fingerprint string      `json:"fingerprint"`
+	Status      int         `json:"status"`
+	Header      http.Header `json:"header"`
+	Body        []byte      `json:"body"`
+}
+
+// IdempotencyMiddleware replays the first response sent for an Idempotency-Key instead of running
+// the handler again. Keys are scoped to the client, so clients can not replay each other's
+// responses. A key reused with a different request is rejected with 422, and a retry that
+// arrives while the first request is still running is rejected with 409. The body is read to tell
+// requests apart, bodies larger than MaxBodySize are rejected with 413.
+// Server errors are not stored so the client can retry them, and requests without the header
+// or with an unavailable Redis are handled as usual.
+func (mw *Middleware) IdempotencyMiddleware() echo.MiddlewareFunc {
+	cfg := mw.config.Idempotency
+
+	methods := cfg.Methods
+	if len(methods) == 0 {
+		methods = []string{http.MethodPost}
+	}
+
+	ttl := cfg.TTL
+	if ttl <= 0 {
+		ttl = defaultIdempotencyTTL
+	}
+
+	lockTTL := cfg.LockTTL
+	if lockTTL <= 0 {
+		lockTTL = defaultIdempotencyLockTTL
+	}
+
+	maxBodySize := cfg.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = defaultIdempotencyBody
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			idempotencyKey := req.Header.Get(HeaderIdempotencyKey)
+			if !cfg.Enable || idempotencyKey == "" || !containsMethod(methods, req.Method) {
+				return next(c)
+			}
+
+			if len(idempotencyKey) > maxIdempotencyKeyLength {
+				return response.ErrorBuilder(appError.BadRequest(appError.ErrInvalidIdempotencyKey)).Send(c)
+			}
+
+			body, err := io.ReadAll(io.LimitReader(req.Body, maxBodySize+1))
+			if err != nil {
+				return err
+			}
+			if int64(len(body)) > maxBodySize {
+				return response.ErrorBuilder(appError.RequestEntityTooLarge(appError.ErrBodyTooLarge)).Send(c)
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			ctx := req.Context()
+			key := mw.redis.GetKeyName("idempotency", hashHex(idempotencyClient(c), req.Method, c.Path(), idempotencyKey))
+			fingerprint := hashHex(req.Method, req.URL.RequestURI(), string(body))
+
+			if stored, err := mw.storedResponse(ctx, key); err != nil {
+				mw.logger.WithContext(ctx).Error("IdempotencyMiddleware", "error", err)
+				return next(c)
+			} else if stored != nil {
+				return replayResponse(c, stored, fingerprint)
+			}
+
+			lock, err := datasource.ObtainLock(ctx, mw.redis, key+":lock", lockTTL, 0)
+			if errors.Is(err, datasource.ErrLockNotObtained) {
+				return response.ErrorBuilder(appError.Conflict(appError.ErrIdempotencyKeyInProgress)).Send(c)
+			}
+			if err != nil {
+				mw.logger.WithContext(ctx).Error("IdempotencyMiddleware", "error", err)
+				return next(c)
+			}
+			defer func() {
+				// The request context may already be canceled, the lock must be released regardless.
+				if err := lock.Release(context.Background()); err != nil && !errors.Is(err, datasource.ErrLockNotHeld) {
+					mw.logger.WithContext(ctx).Error("IdempotencyMiddleware", "error", err)
+				}
+			}()
+
+			// The first request may have finished between the lookup and the lock.
+			if stored, err := mw.storedResponse(ctx, key); err == nil && stored != nil {
+				return replayResponse(c, stored, fingerprint)
+			}
+
+			// The handler runs under the lock: a lost lock cancels it, as a retry may start meanwhile.
+			lockCtx := lock.KeepAlive(ctx, lockTTL/3)
+			c.SetRequest(req.WithContext(lockCtx))
+
+			res := c.Response()
+			recorder := &responseRecorder{ResponseWriter: res.Writer}
+			res.Writer = recorder
+
+			// Run the error handler here so the error response is recorded as well.
+			if err := next(c); err != nil {
+				handleError(c, err)
+			}
+
+			if res.Status >= http.StatusInternalServerError {
+				return nil
+			}
+			if lockCtx.Err() != nil && ctx.Err() == nil {
+				// A retry may have run meanwhile, its response wins.
+				mw.logger.WithContext(ctx).Error("IdempotencyMiddleware", "error", context.Cause(lockCtx))
+				return nil
+			}
+
+			header := res.Header().Clone()
+			for _, k := range []string{HeaderRateLimitLimit, HeaderRateLimitRemaining, HeaderRateLimitReset} {
+				header.Del(k)
+			}
+
+			stored := idempotentResponse{
+				Fingerprint: fingerprint,
+				Status:      res.Status,
+				Header:      header,
+				Body:        recorder.body.Bytes(),
+			}
+			if err := mw.storeResponse(context.Background(), key, stored, ttl); err != nil {
+				mw.logger.WithContext(ctx).Error("IdempotencyMiddleware", "error", err)
+			}
+
+			return nil
+		}
+	}
+}
+
+func (mw *Middleware) storedResponse(ctx context.Context, key string) (*idempotentResponse, error) {
+	data, err := mw.redis.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var stored idempotentResponse
+	if err := json.Unmarshal([]byte(data), &stored); err != nil {
+		return nil, err
+	}
+
+	return &stored, nil
+}
+
+func (mw *Middleware) storeResponse(ctx context.Context, key string, stored idempotentResponse, ttl time.Duration) error {
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+
+	return mw.redis.Set(ctx, key, data, ttl)
+}
+
+// replayResponse sends stored again, unless it was the response to a different request.
+func replayResponse(c echo.Context, stored *idempotentResponse, fingerprint string) error {
+	if stored.Fingerprint != fingerprint {
+		return response.ErrorBuilder(appError.UnprocessableEntity(appError.ErrIdempotencyKeyReused)).Send(c)
+	}
+
+	header := c.Response().Header()
+	for k, values := range stored.Header {
+		// Headers already set by earlier middlewares, such as rate limits, describe this request.
+		if _, ok := header[k]; ok {
+			continue
+		}
+		header[k] = values
+	}
+	header.Set(HeaderIdempotentReplayed, "true")
+
+	return c.Blob(stored.Status, header.Get(echo.HeaderContentType), stored.Body)
+}
+
+// idempotencyClient identifies the client sending an Idempotency-Key: its user or its API key.
+// Anonymous clients share one scope, not keyed by IP since mobile clients change IP between
+// retries; their random keys and the fingerprint check keep them apart.
+func idempotencyClient(c echo.Context) string {
+	if userID := c.Get(ContextKeyUserID); userID != nil {
+		return fmt.Sprintf("user:%v", userID)
+	}
+	if apiKey := c.Request().Header.Get(HeaderAPIKey); apiKey != "" {
+		return apiKeyID(apiKey)
+	}
+	return "anonymous"
+}
+
+func containsMethod(methods []string, method string) bool {
+	for _, m := range methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+func hashHex(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder copies everything written to the response.
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := r.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, http.ErrNotSupported
+}
@@ -0,0 +1,221 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nutsp/golang-clean-architecture/config"
+	"github.com/nutsp/golang-clean-architecture/internal/middlewares"
+	appError "github.com/nutsp/golang-clean-architecture/pkg/apperror"
+	"github.com/stretchr/testify/assert"
+)
+
+func newIdempotentServer(handler echo.HandlerFunc) *echo.Echo {
+	return newIdempotentServerWithConfig(config.IdempotencyConfig{
+		Enable:      true,
+		TTL:         time.Hour,
+		LockTTL:     time.Minute,
+		MaxBodySize: 64,
+	}, handler)
+}
+
+func newIdempotentServerWithConfig(cfg config.IdempotencyConfig, handler echo.HandlerFunc) *echo.Echo {
+	mw := newTestMiddleware(middlewares.MiddlewareDependencies{
+		Config: &config.Config{Idempotency: cfg},
+	})
+
+	e := echo.New()
+	e.Use(mw.IdempotencyMiddleware())
+	e.POST("/api/v1/users", handler)
+
+	return e
+}
+
+func postUser(e *echo.Echo, key, body string) *httptest.ResponseRecorder {
+	return postUserAs(e, nil, key, body)
+}
+
+func postUserAs(e *echo.Echo, header http.Header, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/users", strings.NewReader(body))
+	for k, values := range header {
+		for _, v := range values {
+			req.Header.Add(k, v)
+		}
+	}
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if key != "" {
+		req.Header.Set(middlewares.HeaderIdempotencyKey, key)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotencyMiddlewareReplaysFirstResponse(t *testing.T) {
+	var calls int32
+	e := newIdempotentServer(func(c echo.Context) error {
+		n := atomic.AddInt32(&calls, 1)
+		return c.JSON(http.StatusCreated, map[string]int32{"id": n})
+	})
+
+	first := postUser(e, "key-1", `{"email":"a@example.com"}`)
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Empty(t, first.Header().Get(middlewares.HeaderIdempotentReplayed))
+
+	retry := postUser(e, "key-1", `{"email":"a@example.com"}`)
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get(middlewares.HeaderIdempotentReplayed))
+	assert.Equal(t, first.Header().Get(echo.HeaderContentType), retry.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	other := postUser(e, "key-2", `{"email":"a@example.com"}`)
+	assert.Equal(t, http.StatusCreated, other.Code)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	postUser(e, "", `{"email":"a@example.com"}`)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestIdempotencyMiddlewareRejectsDifferentPayload(t *testing.T) {
+	e := newIdempotentServer(func(c echo.Context) error {
+		return c.NoContent(http.StatusCreated)
+	})
+
+	assert.Equal(t, http.StatusCreated, postUser(e, "key-1", `{"email":"a@example.com"}`).Code)
+
+	rec := postUser(e, "key-1", `{"email":"b@example.com"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Contains(t, rec.Body.String(), appError.ErrIdempotencyKeyReused.Error())
+}
+
+func TestIdempotencyMiddlewareStoresErrorResponses(t *testing.T) {
+	var calls int32
+	e := newIdempotentServer(func(c echo.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return echo.NewHTTPError(http.StatusConflict, appError.ErrEmailAlreadyExist.Error())
+	})
+
+	assert.Equal(t, http.StatusConflict, postUser(e, "key-1", `{}`).Code)
+	assert.Equal(t, http.StatusConflict, postUser(e, "key-1", `{}`).Code)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestIdempotencyMiddlewareDoesNotStoreServerErrors(t *testing.T) {
+	var calls int32
+	e := newIdempotentServer(func(c echo.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	})
+
+	assert.Equal(t, http.StatusInternalServerError, postUser(e, "key-1", `{}`).Code)
+	assert.Equal(t, http.StatusInternalServerError, postUser(e, "key-1", `{}`).Code)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestIdempotencyMiddlewareRejectsConcurrentDuplicate(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	e := newIdempotentServer(func(c echo.Context) error {
+		close(started)
+		<-release
+		return c.NoContent(http.StatusCreated)
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- postUser(e, "key-1", `{}`)
+	}()
+
+	<-started
+	rec := postUser(e, "key-1", `{}`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Contains(t, rec.Body.String(), appError.ErrIdempotencyKeyInProgress.Error())
+
+	close(release)
+	assert.Equal(t, http.StatusCreated, (<-done).Code)
+	assert.Equal(t, http.StatusCreated, postUser(e, "key-1", `{}`).Code)
+}
+
+func TestIdempotencyMiddlewareScopesKeysToClients(t *testing.T) {
+	var calls int32
+	e := newIdempotentServer(func(c echo.Context) error {
+		n := atomic.AddInt32(&calls, 1)
+		return c.JSON(http.StatusCreated, map[string]int32{"id": n})
+	})
+	alice := http.Header{middlewares.HeaderAPIKey: {"alice-key"}}
+	bob := http.Header{middlewares.HeaderAPIKey: {"bob-key"}}
+
+	first := postUserAs(e, alice, "key-1", `{}`)
+	other := postUserAs(e, bob, "key-1", `{}`)
+	assert.Empty(t, other.Header().Get(middlewares.HeaderIdempotentReplayed))
+	assert.NotEqual(t, first.Body.String(), other.Body.String())
+
+	retry := postUserAs(e, alice, "key-1", `{}`)
+	assert.Equal(t, "true", retry.Header().Get(middlewares.HeaderIdempotentReplayed))
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestIdempotencyMiddlewareReplaysAnonymousRetryFromAnotherIP(t *testing.T) {
+	var calls int32
+	e := newIdempotentServer(func(c echo.Context) error {
+		n := atomic.AddInt32(&calls, 1)
+		return c.JSON(http.StatusCreated, map[string]int32{"id": n})
+	})
+	e.IPExtractor = func(req *http.Request) string { return req.Header.Get(echo.HeaderXForwardedFor) }
+
+	first := postUserAs(e, http.Header{echo.HeaderXForwardedFor: {"203.0.113.1"}}, "key-1", `{}`)
+	retry := postUserAs(e, http.Header{echo.HeaderXForwardedFor: {"198.51.100.7"}}, "key-1", `{}`)
+	assert.Equal(t, "true", retry.Header().Get(middlewares.HeaderIdempotentReplayed))
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestIdempotencyMiddlewareRejectsLargeBody(t *testing.T) {
+	var calls int32
+	e := newIdempotentServer(func(c echo.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return c.NoContent(http.StatusCreated)
+	})
+
+	rec := postUser(e, "key-1", `{"name":"`+strings.Repeat("a", 64)+`"}`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.Contains(t, rec.Body.String(), appError.ErrBodyTooLarge.Error())
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+
+	// Without the header the body is not read.
+	assert.Equal(t, http.StatusCreated, postUser(e, "", `{"name":"`+strings.Repeat("a", 64)+`"}`).Code)
+}
+
+func TestIdempotencyMiddlewareRenewsLock(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	e := newIdempotentServerWithConfig(config.IdempotencyConfig{
+		Enable:  true,
+		TTL:     time.Hour,
+		LockTTL: 30 * time.Millisecond,
+	}, func(c echo.Context) error {
+		close(started)
+		<-release
+		return c.NoContent(http.StatusCreated)
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- postUser(e, "key-1", `{}`)
+	}()
+
+	<-started
+	// Well past the lock ttl, the first request still holds the key.
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, http.StatusConflict, postUser(e, "key-1", `{}`).Code)
+
+	close(release)
+	assert.Equal(t, http.StatusCreated, (<-done).Code)
+}
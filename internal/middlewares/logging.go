@@ -4,68 +4,188 @@ import (
 	"bytes"
 	"encoding/json"
 	"io"
+	"math/rand"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	defaultMaxLoggedBodySize = 4 << 10
+	redacted                 = "[REDACTED]"
+)
+
+var (
+	defaultLoggedContentTypes = []string{echo.MIMEApplicationJSON, echo.MIMEApplicationForm}
+	defaultRedactedFields     = []string{"password", "token", "secret"}
+)
+
+// LoggingMiddleware writes one access log line per request, once the error handler
+// has run so the line carries the final status. Secrets in the body are redacted,
+// and bodies are only read up to the configured size.
 func (mw *Middleware) LoggingMiddleware() echo.MiddlewareFunc {
+	cfg := mw.config.RequestLog
+
+	maxBodySize := cfg.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = defaultMaxLoggedBodySize
+	}
+	contentTypes := cfg.ContentTypes
+	if len(contentTypes) == 0 {
+		contentTypes = defaultLoggedContentTypes
+	}
+	redactFields := cfg.RedactFields
+	if len(redactFields) == 0 {
+		redactFields = defaultRedactedFields
+	}
+
+	redact := make(map[string]bool, len(redactFields))
+	for _, field := range redactFields {
+		redact[strings.ToLower(field)] = true
+	}
+	skip := make(map[string]bool, len(cfg.SkipPaths))
+	for _, path := range cfg.SkipPaths {
+		skip[path] = true
+	}
+
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			req := c.Request()
-			res := c.Response()
+			if skip[req.URL.Path] {
+				return next(c)
+			}
 
-			// Track the start time of the request
 			startTime := time.Now()
 
-			if errCheck := func() error {
-				// Check if the request has a body
-				if req.Body != nil {
-					// Read the request body
-					reqBody, err := io.ReadAll(req.Body)
-					if err != nil {
-						return err
-					}
-
-					// Restore the request body so it can be used in subsequent handlers
-					req.Body = io.NopCloser(bytes.NewBuffer(reqBody))
-
-					// Include request body as JSON in the log
-					var requestBodyJSON interface{}
-					if err := json.Unmarshal(reqBody, &requestBodyJSON); err != nil {
-						return err
-					}
-
-					// Include request body in the log
-					mw.logger.Info("Received request",
-						"method", req.Method,
-						"path", req.URL.Path,
-						"ip", req.RemoteAddr,
-						"body", requestBodyJSON,
-					)
-				}
-
-				return nil
-			}(); errCheck != nil {
-				// Log trace and span IDs using zerolog
-				mw.logger.Info("Received request",
-					"method", req.Method,
-					"path", req.URL.Path,
-					"ip", req.RemoteAddr,
-				)
+			var body []interface{}
+			if cfg.Body {
+				body = logBody(req, contentTypes, maxBodySize, redact)
 			}
 
-			// Continue to the next middleware/handler
 			err := next(c)
+			if err != nil {
+				handleError(c, err)
+			}
+
+			res := c.Response()
+			failed := res.Status >= http.StatusBadRequest
+			if !failed && cfg.SampleRatio > 0 && cfg.SampleRatio < 1 && rand.Float64() >= cfg.SampleRatio {
+				return nil
+			}
 
-			// Log response details using zerolog
-			mw.logger.Info("Sent response",
+			fields := []interface{}{
+				"method", req.Method,
+				"path", req.URL.Path,
+				"route", c.Path(),
 				"status", res.Status,
 				"size", res.Size,
 				"duration", time.Since(startTime),
-			)
+				"ip", c.RealIP(),
+				"user_agent", req.UserAgent(),
+			}
+			fields = append(fields, body...)
+
+			logger := mw.logger.WithContext(req.Context())
+			if err := requestError(c, err); err != nil {
+				fields = append(fields, "error", err)
+			}
+			if res.Status >= http.StatusInternalServerError {
+				logger.Error("Handled request", fields...)
+			} else {
+				logger.Info("Handled request", fields...)
+			}
 
-			return err
+			return nil
 		}
 	}
 }
+
+// logBody returns the log fields describing the body of req. The body is read up to
+// maxSize bytes and the part read is put back, so handlers still see the whole body.
+// Only JSON and form bodies, whose secret fields can be redacted, are logged.
+func logBody(req *http.Request, contentTypes []string, maxSize int, redact map[string]bool) []interface{} {
+	if req.Body == nil || req.Body == http.NoBody || req.ContentLength == 0 {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get(echo.HeaderContentType))
+	if !containsFold(contentTypes, mediaType) {
+		return []interface{}{"body_omitted", true}
+	}
+	if req.ContentLength > int64(maxSize) {
+		return []interface{}{"body_truncated", true}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(req.Body, int64(maxSize)+1))
+	req.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(data), req.Body), Closer: req.Body}
+	if err != nil {
+		return []interface{}{"body_error", err.Error()}
+	}
+	// A truncated body cannot be parsed, so its secrets could not be redacted.
+	if len(data) > maxSize {
+		return []interface{}{"body_truncated", true}
+	}
+
+	switch mediaType {
+	case echo.MIMEApplicationJSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return []interface{}{"body_error", "malformed json"}
+		}
+		return []interface{}{"body", redactJSON(value, redact)}
+	case echo.MIMEApplicationForm:
+		values, err := url.ParseQuery(string(data))
+		if err != nil {
+			return []interface{}{"body_error", "malformed form"}
+		}
+		for key := range values {
+			if redact[strings.ToLower(key)] {
+				values[key] = []string{redacted}
+			}
+		}
+		return []interface{}{"body", values}
+	default:
+		return []interface{}{"body_omitted", true}
+	}
+}
+
+// redactJSON replaces the values of the redacted fields of value, at any depth.
+func redactJSON(value interface{}, redact map[string]bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if redact[strings.ToLower(key)] {
+				v[key] = redacted
+				continue
+			}
+			v[key] = redactJSON(field, redact)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactJSON(item, redact)
+		}
+	}
+	return value
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// readCloser reads the restored body but closes the original one.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
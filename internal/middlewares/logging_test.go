@@ -0,0 +1,224 @@
+package middlewares_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nutsp/golang-clean-architecture/config"
+	"github.com/nutsp/golang-clean-architecture/internal/middlewares"
+	appError "github.com/nutsp/golang-clean-architecture/pkg/apperror"
+	"github.com/nutsp/golang-clean-architecture/pkg/observability"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// logLine is a line written to a recordingLogger, with its fields by key.
+type logLine struct {
+	level  string
+	msg    string
+	fields map[string]interface{}
+}
+
+// recordingLogger keeps the lines written to it, for assertions.
+type recordingLogger struct {
+	mu     *sync.Mutex
+	lines  *[]logLine
+	fields []interface{}
+}
+
+func newRecordingLogger() *recordingLogger {
+	return &recordingLogger{mu: &sync.Mutex{}, lines: &[]logLine{}}
+}
+
+func (l *recordingLogger) log(level, msg string, fields []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	line := logLine{level: level, msg: msg, fields: map[string]interface{}{}}
+	fields = append(append([]interface{}{}, l.fields...), fields...)
+	for i := 0; i+1 < len(fields); i += 2 {
+		line.fields[fields[i].(string)] = fields[i+1]
+	}
+	*l.lines = append(*l.lines, line)
+}
+
+func (l *recordingLogger) Info(msg string, fields ...interface{})  { l.log("info", msg, fields) }
+func (l *recordingLogger) Error(msg string, fields ...interface{}) { l.log("error", msg, fields) }
+func (l *recordingLogger) Debug(msg string, fields ...interface{}) { l.log("debug", msg, fields) }
+func (l *recordingLogger) Warn(msg string, fields ...interface{})  { l.log("warn", msg, fields) }
+func (l *recordingLogger) Fatal(msg string, fields ...interface{}) { l.log("fatal", msg, fields) }
+
+func (l *recordingLogger) With(fields ...interface{}) observability.Logger {
+	return &recordingLogger{mu: l.mu, lines: l.lines, fields: append(append([]interface{}{}, l.fields...), fields...)}
+}
+
+func (l *recordingLogger) WithContext(ctx context.Context) observability.Logger {
+	requestID := observability.RequestIDFromContext(ctx)
+	if requestID == "" {
+		return l
+	}
+	return l.With(observability.FieldRequestID, requestID)
+}
+
+func (l *recordingLogger) Lines() []logLine {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]logLine(nil), *l.lines...)
+}
+
+func newLoggingServer(t *testing.T, cfg config.RequestLogConfig) (*echo.Echo, *recordingLogger) {
+	t.Helper()
+
+	logger := newRecordingLogger()
+	mw := newTestMiddleware(middlewares.MiddlewareDependencies{
+		Config: &config.Config{RequestLog: cfg},
+		Logger: logger,
+	})
+
+	e := newTestServer()
+	e.Use(mw.RequestIDMiddleware())
+	e.Use(mw.LoggingMiddleware())
+	e.POST("/api/v1/users", func(c echo.Context) error {
+		body, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return err
+		}
+		return c.Blob(http.StatusCreated, echo.MIMETextPlain, body)
+	})
+	e.GET("/api/v1/users/:id", func(c echo.Context) error {
+		if c.Param("id") == "0" {
+			return appError.BadRequest(appError.ErrInvalidIsActive)
+		}
+		return errors.New("database is down")
+	})
+	e.GET("/healthz", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	return e, logger
+}
+
+func TestLoggingMiddlewareBody(t *testing.T) {
+	e, logger := newLoggingServer(t, config.RequestLogConfig{
+		Body:         true,
+		MaxBodySize:  64,
+		RedactFields: []string{"password", "Token"},
+	})
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		field       string
+		logged      interface{}
+	}{
+		{
+			name:        "redacts json fields at any depth",
+			contentType: echo.MIMEApplicationJSONCharsetUTF8,
+			body:        `{"email":"a@b.c","password":"1234","auth":[{"token":"t"}]}`,
+			field:       "body",
+			logged: map[string]interface{}{
+				"email":    "a@b.c",
+				"password": "[REDACTED]",
+				"auth":     []interface{}{map[string]interface{}{"token": "[REDACTED]"}},
+			},
+		},
+		{
+			name:        "redacts form fields",
+			contentType: echo.MIMEApplicationForm,
+			body:        "email=a%40b.c&PASSWORD=1234",
+			field:       "body",
+			logged:      url.Values{"email": {"a@b.c"}, "PASSWORD": {"[REDACTED]"}},
+		},
+		{
+			name:        "does not log plain text",
+			contentType: echo.MIMETextPlain,
+			body:        "password=1234",
+			field:       "body_omitted",
+			logged:      true,
+		},
+		{
+			name:        "does not log malformed json",
+			contentType: echo.MIMEApplicationJSON,
+			body:        `{"password":"1234"`,
+			field:       "body_error",
+			logged:      "malformed json",
+		},
+		{
+			name:        "does not log other content types",
+			contentType: echo.MIMEOctetStream,
+			body:        "\x00\x01",
+			field:       "body_omitted",
+			logged:      true,
+		},
+		{
+			name:        "does not log oversized bodies",
+			contentType: echo.MIMEApplicationJSON,
+			body:        `{"password":"` + strings.Repeat("x", 64) + `"}`,
+			field:       "body_truncated",
+			logged:      true,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/users", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, tt.contentType)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			// The handler still reads the whole body.
+			assert.Equal(t, tt.body, rec.Body.String())
+
+			lines := logger.Lines()
+			require.Len(t, lines, i+1)
+			assert.Equal(t, tt.logged, lines[i].fields[tt.field])
+		})
+	}
+}
+
+func TestLoggingMiddlewareAccessLog(t *testing.T) {
+	e, logger := newLoggingServer(t, config.RequestLogConfig{SkipPaths: []string{"/healthz"}})
+
+	for _, path := range []string{"/api/v1/users/0", "/api/v1/users/1", "/healthz"} {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	lines := logger.Lines()
+	require.Len(t, lines, 2)
+
+	assert.Equal(t, "info", lines[0].level)
+	assert.Equal(t, http.MethodGet, lines[0].fields["method"])
+	assert.Equal(t, "/api/v1/users/0", lines[0].fields["path"])
+	assert.Equal(t, "/api/v1/users/:id", lines[0].fields["route"])
+	assert.Equal(t, http.StatusBadRequest, lines[0].fields["status"])
+	assert.NotEmpty(t, lines[0].fields[observability.FieldRequestID])
+	assert.NotContains(t, lines[0].fields, "body")
+
+	assert.Equal(t, "error", lines[1].level)
+	assert.Equal(t, http.StatusInternalServerError, lines[1].fields["status"])
+	assert.EqualError(t, lines[1].fields["error"].(error), "database is down")
+}
+
+func TestLoggingMiddlewareSampling(t *testing.T) {
+	e, logger := newLoggingServer(t, config.RequestLogConfig{SampleRatio: 1e-9})
+
+	for i := 0; i < 10; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/users", strings.NewReader("hello"))
+		e.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/users/0", nil))
+
+	// Failed requests are always logged.
+	lines := logger.Lines()
+	require.Len(t, lines, 1)
+	assert.Equal(t, http.StatusBadRequest, lines[0].fields["status"])
+}
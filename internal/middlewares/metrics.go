@@ -0,0 +1,56 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	appError "github.com/nutsp/golang-clean-architecture/pkg/apperror"
+)
+
+// routeUnmatched labels the requests that matched no route, so unknown paths do not
+// create a time series each.
+const routeUnmatched = "unmatched"
+
+// MetricsMiddleware records the count, errors and latency of every request per route.
+// It runs first so the latency covers every other middleware.
+func (mw *Middleware) MetricsMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			startTime := time.Now()
+
+			err := next(c)
+			if err != nil {
+				handleError(c, err)
+			}
+
+			req := c.Request()
+			status := c.Response().Status
+
+			route := c.Path()
+			if route == "" || status == http.StatusNotFound && route == "/*" {
+				route = routeUnmatched
+			}
+
+			mw.metrics.Observe(req.Method, route, status, errorCode(requestError(c, err), status), time.Since(startTime))
+
+			return nil
+		}
+	}
+}
+
+// errorCode returns the apperror code of a failed request, such as "bad_request",
+// or one derived from the status when the error is not an apperror.
+func errorCode(err error, status int) string {
+	var appErr *appError.AppError
+	if errors.As(err, &appErr) {
+		return strings.ToLower(appErr.Message)
+	}
+
+	if status < http.StatusBadRequest {
+		return ""
+	}
+	return strings.ToLower(strings.ReplaceAll(http.StatusText(status), " ", "_"))
+}
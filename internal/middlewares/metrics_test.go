@@ -0,0 +1,47 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nutsp/golang-clean-architecture/internal/middlewares"
+	appError "github.com/nutsp/golang-clean-architecture/pkg/apperror"
+	"github.com/nutsp/golang-clean-architecture/pkg/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	httpMetrics := metrics.NewHTTPMetrics()
+	registry, err := metrics.NewRegistry(httpMetrics)
+	require.NoError(t, err)
+
+	mw := newTestMiddleware(middlewares.MiddlewareDependencies{
+		Metrics: httpMetrics,
+	})
+
+	e := newTestServer()
+	e.Use(mw.MetricsMiddleware())
+	e.GET("/api/v1/users/:id", func(c echo.Context) error {
+		if c.Param("id") == "0" {
+			return appError.BadRequest(appError.ErrInvalidIsActive)
+		}
+		return c.NoContent(http.StatusOK)
+	})
+
+	for _, path := range []string{"/api/v1/users/1", "/api/v1/users/2", "/api/v1/users/0", "/unknown"} {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	rec := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := rec.Body.String()
+	assert.Contains(t, body, `http_server_requests_total{method="GET",route="/api/v1/users/:id",status="200"} 2`)
+	assert.Contains(t, body, `http_server_requests_total{method="GET",route="/api/v1/users/:id",status="400"} 1`)
+	assert.Contains(t, body, `http_server_errors_total{code="bad_request",method="GET",route="/api/v1/users/:id"} 1`)
+	assert.Contains(t, body, `http_server_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.Contains(t, body, `http_server_errors_total{code="not_found",method="GET",route="unmatched"} 1`)
+}
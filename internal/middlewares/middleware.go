@@ -1,26 +1,71 @@
 package middlewares
 
 import (
+	"sync/atomic"
+
 	"github.com/labstack/echo/v4"
+	"github.com/nutsp/golang-clean-architecture/config"
+	"github.com/nutsp/golang-clean-architecture/pkg/datasource"
+	"github.com/nutsp/golang-clean-architecture/pkg/featureflag"
+	"github.com/nutsp/golang-clean-architecture/pkg/metrics"
 	"github.com/nutsp/golang-clean-architecture/pkg/observability"
+	"github.com/nutsp/golang-clean-architecture/pkg/ratelimit"
 	"go.uber.org/dig"
 )
 
 type IMiddleware interface {
+	RequestIDMiddleware() echo.MiddlewareFunc
+	MetricsMiddleware() echo.MiddlewareFunc
+	TracingMiddleware() echo.MiddlewareFunc
 	LoggingMiddleware() echo.MiddlewareFunc
+	RateLimitMiddleware() echo.MiddlewareFunc
+	IdempotencyMiddleware() echo.MiddlewareFunc
+	AdminMiddleware() echo.MiddlewareFunc
+	MetricsAccessMiddleware() echo.MiddlewareFunc
+	AuthenticationMiddleware() echo.MiddlewareFunc
+	SessionRevocationMiddleware(sessions SessionRevocations) echo.MiddlewareFunc
+	RecoverMiddleware() echo.MiddlewareFunc
+	FeatureFlagMiddleware() echo.MiddlewareFunc
+	RequireFeatureMiddleware(name string) echo.MiddlewareFunc
 }
 
 type Middleware struct {
-	logger observability.Logger
+	config   *config.Config
+	logger   observability.Logger
+	limiter  ratelimit.Limiter
+	redis    datasource.IRedisClient
+	metrics  *metrics.HTTPMetrics
+	features featureflag.Flags
+
+	rateLimit atomic.Pointer[config.RateLimitConfig] // Replaced when the configuration is reloaded.
 }
 
 type MiddlewareDependencies struct {
 	dig.In
-	Logger observability.Logger `name:"Logger"`
+	Config      *config.Config
+	Logger      observability.Logger    `name:"Logger"`
+	RedisClient datasource.IRedisClient `name:"RedisClient"`
+	Metrics     *metrics.HTTPMetrics
+	Reloader    *config.Reloader  `optional:"true"`
+	Features    featureflag.Flags `name:"FeatureFlags" optional:"true"`
 }
 
 func NewMiddleware(deps MiddlewareDependencies) *Middleware {
-	return &Middleware{
-		logger: deps.Logger,
+	mw := &Middleware{
+		config:   deps.Config,
+		logger:   deps.Logger,
+		limiter:  ratelimit.NewSlidingWindow(deps.RedisClient),
+		redis:    deps.RedisClient,
+		metrics:  deps.Metrics,
+		features: deps.Features,
 	}
+
+	mw.rateLimit.Store(&deps.Config.RateLimit)
+	if deps.Reloader != nil {
+		deps.Reloader.Subscribe("RateLimit", func(cfg *config.Config) {
+			mw.rateLimit.Store(&cfg.RateLimit)
+		})
+	}
+
+	return mw
 }
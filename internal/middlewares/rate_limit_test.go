@@ -0,0 +1,128 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nutsp/golang-clean-architecture/config"
+	"github.com/nutsp/golang-clean-architecture/internal/middlewares"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRateLimitedServer(cfg config.RateLimitConfig) *echo.Echo {
+	mw := newTestMiddleware(middlewares.MiddlewareDependencies{
+		Config: &config.Config{RateLimit: cfg},
+	})
+
+	e := echo.New()
+	e.Use(mw.RateLimitMiddleware())
+
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+	e.POST("/api/v1/users", ok)
+	e.GET("/api/v1/users/:id", ok)
+
+	return e
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	e := newRateLimitedServer(config.RateLimitConfig{
+		Enable: true,
+		Limit:  3,
+		Window: time.Minute,
+		Routes: []config.RateLimitRoute{
+			{Method: http.MethodPost, Path: "/api/v1/users", Limit: 1},
+		},
+	})
+
+	rec := serve(e, http.MethodPost, "/api/v1/users", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get(middlewares.HeaderRateLimitLimit))
+	assert.Equal(t, "0", rec.Header().Get(middlewares.HeaderRateLimitRemaining))
+	assert.NotEmpty(t, rec.Header().Get(middlewares.HeaderRateLimitReset))
+
+	rec = serve(e, http.MethodPost, "/api/v1/users", nil)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get(echo.HeaderRetryAfter))
+	assert.Contains(t, rec.Body.String(), "too_many_requests")
+
+	// Other routes use the default limit, shared by every path of the route.
+	for i := 0; i < 3; i++ {
+		rec = serve(e, http.MethodGet, "/api/v1/users/1", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+	rec = serve(e, http.MethodGet, "/api/v1/users/2", nil)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+}
+
+func TestRateLimitMiddlewareKeyByAPIKey(t *testing.T) {
+	e := newRateLimitedServer(config.RateLimitConfig{
+		Enable: true,
+		Limit:  1,
+		Window: time.Minute,
+		KeyBy:  middlewares.RateLimitKeyByAPIKey,
+	})
+
+	first := http.Header{middlewares.HeaderAPIKey: {"first"}}
+	second := http.Header{middlewares.HeaderAPIKey: {"second"}}
+
+	assert.Equal(t, http.StatusOK, serve(e, http.MethodGet, "/api/v1/users/1", first).Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve(e, http.MethodGet, "/api/v1/users/1", first).Code)
+	assert.Equal(t, http.StatusOK, serve(e, http.MethodGet, "/api/v1/users/1", second).Code)
+}
+
+func TestRateLimitMiddlewareDisabled(t *testing.T) {
+	e := newRateLimitedServer(config.RateLimitConfig{Limit: 1, Window: time.Minute})
+
+	for i := 0; i < 3; i++ {
+		rec := serve(e, http.MethodGet, "/api/v1/users/1", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get(middlewares.HeaderRateLimitLimit))
+	}
+}
+
+func TestRateLimitMiddlewareReload(t *testing.T) {
+	const base = `
+App:
+  Name: app
+Database:
+  Host: 127.0.0.1
+  Name: test
+  User: root
+Redis:
+  Addr: 127.0.0.1:6379
+JWT:
+  Key: secret
+RateLimit:
+  Enable: true
+  Window: 1m
+`
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(base+"  Limit: 1\n"), 0o600))
+
+	reloader, err := config.NewReloader(dir, "config")
+	require.NoError(t, err)
+	mw := newTestMiddleware(middlewares.MiddlewareDependencies{
+		Config:   reloader.Current(),
+		Reloader: reloader,
+	})
+
+	e := echo.New()
+	e.Use(mw.RateLimitMiddleware())
+	e.GET("/api/v1/users/:id", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+	assert.Equal(t, http.StatusOK, serve(e, http.MethodGet, "/api/v1/users/1", nil).Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve(e, http.MethodGet, "/api/v1/users/1", nil).Code)
+
+	require.NoError(t, os.WriteFile(file, []byte(base+"  Limit: 3\n"), 0o600))
+	require.NoError(t, reloader.Reload())
+
+	rec := serve(e, http.MethodGet, "/api/v1/users/1", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "3", rec.Header().Get(middlewares.HeaderRateLimitLimit))
+}
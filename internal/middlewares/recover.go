@@ -0,0 +1,40 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/labstack/echo/v4"
+	appError "github.com/nutsp/golang-clean-architecture/pkg/apperror"
+)
+
+// errPanic is what the client sees of a panic, its value and stack only go to the logs.
+var errPanic = errors.New("internal server error")
+
+// RecoverMiddleware turns a panic of the inner middlewares and the handler into
+// a 500 response, and logs the panic with its stack.
+func (mw *Middleware) RecoverMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			defer func() {
+				r := recover()
+				if r == nil {
+					return
+				}
+				// The net/http sentinel aborting a response must reach the server.
+				if r == http.ErrAbortHandler {
+					panic(r)
+				}
+
+				mw.logger.WithContext(c.Request().Context()).Error("RecoverMiddleware",
+					"panic", r,
+					"stack", string(debug.Stack()),
+				)
+				err = appError.InternalServerError(errPanic)
+			}()
+
+			return next(c)
+		}
+	}
+}
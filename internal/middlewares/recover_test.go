@@ -0,0 +1,44 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nutsp/golang-clean-architecture/internal/middlewares"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoverMiddleware(t *testing.T) {
+	logger := newRecordingLogger()
+	mw := newTestMiddleware(middlewares.MiddlewareDependencies{
+		Logger: logger,
+	})
+
+	e := echo.New()
+	e.Use(mw.RequestIDMiddleware())
+	e.Use(mw.LoggingMiddleware())
+	e.Use(mw.RecoverMiddleware())
+	e.GET("/panic", func(c echo.Context) error {
+		var users map[string]int
+		users["john"]++
+		return nil
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "nil map")
+
+	lines := logger.Lines()
+	require.Len(t, lines, 2)
+	assert.Equal(t, "RecoverMiddleware", lines[0].msg)
+	assert.Contains(t, lines[0].fields["panic"].(error).Error(), "assignment to entry in nil map")
+	assert.Contains(t, lines[0].fields["stack"], "recover_test.go")
+	assert.NotEmpty(t, lines[0].fields["request_id"])
+	// The access log still records the request.
+	assert.Equal(t, http.StatusInternalServerError, lines[1].fields["status"])
+}
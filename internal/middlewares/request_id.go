@@ -0,0 +1,57 @@
+package middlewares
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nutsp/golang-clean-architecture/pkg/observability"
+)
+
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware accepts the X-Request-ID sent by the client or generates one, echoes it
+// in the response and stores it in the request context. It must run first so every other
+// middleware, use case and outbound call sees the ID.
+func (mw *Middleware) RequestIDMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			requestID := req.Header.Get(echo.HeaderXRequestID)
+			if !validRequestID(requestID) {
+				requestID = newRequestID()
+			}
+
+			c.Response().Header().Set(echo.HeaderXRequestID, requestID)
+			c.SetRequest(req.WithContext(observability.ContextWithRequestID(req.Context(), requestID)))
+
+			return next(c)
+		}
+	}
+}
+
+// validRequestID rejects IDs that could forge log lines or blow up log storage.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range requestID {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
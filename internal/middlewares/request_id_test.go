@@ -0,0 +1,53 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nutsp/golang-clean-architecture/internal/middlewares"
+	"github.com/nutsp/golang-clean-architecture/pkg/observability"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	mw := newTestMiddleware(middlewares.MiddlewareDependencies{})
+
+	e := echo.New()
+	e.Use(mw.RequestIDMiddleware())
+	e.GET("/", func(c echo.Context) error {
+		return c.String(http.StatusOK, observability.RequestIDFromContext(c.Request().Context()))
+	})
+
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{name: "accepts the client ID", incoming: "client-id:42", keep: true},
+		{name: "generates a missing ID"},
+		{name: "replaces an ID with invalid characters", incoming: "id\nforged log line"},
+		{name: "replaces an oversized ID", incoming: strings.Repeat("a", 129)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.incoming != "" {
+				req.Header.Set(echo.HeaderXRequestID, tt.incoming)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			requestID := rec.Header().Get(echo.HeaderXRequestID)
+			assert.Equal(t, requestID, rec.Body.String())
+			if tt.keep {
+				assert.Equal(t, tt.incoming, requestID)
+			} else {
+				assert.Len(t, requestID, 32)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package middlewares_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nutsp/golang-clean-architecture/config"
+	"github.com/nutsp/golang-clean-architecture/internal/middlewares"
+	"github.com/nutsp/golang-clean-architecture/pkg/observability"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracingMiddleware(t *testing.T) {
+	tp, err := observability.NewTracerProvider(config.AppConfig{Name: "test"}, config.ObservabilityConfig{
+		Enable: true,
+		Mode:   observability.TracingModeMemory,
+	})
+	require.NoError(t, err)
+	defer tp.Shutdown(context.Background())
+
+	mw := newTestMiddleware(middlewares.MiddlewareDependencies{})
+
+	e := echo.New()
+	e.Use(mw.TracingMiddleware())
+	e.GET("/api/v1/users/:id", func(c echo.Context) error {
+		_, span := observability.StartSpan(c.Request().Context(), "UserUsecase.GetUserInfo")
+		span.End()
+		return echo.NewHTTPError(http.StatusBadGateway)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+
+	spans := tp.Spans()
+	require.Len(t, spans, 2)
+
+	child, server := spans[0], spans[1]
+	assert.Equal(t, "UserUsecase.GetUserInfo", child.Name)
+	assert.Equal(t, server.SpanContext.SpanID(), child.Parent.SpanID())
+
+	assert.Equal(t, "GET /api/v1/users/:id", server.Name)
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID().String())
+	assert.Equal(t, codes.Error, server.Status.Code)
+	assert.Contains(t, server.Attributes, semconv.HTTPResponseStatusCode(http.StatusBadGateway))
+	assert.Contains(t, server.Attributes, semconv.HTTPRoute("/api/v1/users/:id"))
+}
@@ -0,0 +1,63 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/usecase/auth_usecase.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIAuthUsecase is a mock of IAuthUsecase interface.
+type MockIAuthUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockIAuthUsecaseMockRecorder
+}
+
+// MockIAuthUsecaseMockRecorder is the mock recorder for MockIAuthUsecase.
+type MockIAuthUsecaseMockRecorder struct {
+	mock *MockIAuthUsecase
+}
+
+// NewMockIAuthUsecase creates a new mock instance.
+func NewMockIAuthUsecase(ctrl *gomock.Controller) *MockIAuthUsecase {
+	mock := &MockIAuthUsecase{ctrl: ctrl}
+	mock.recorder = &MockIAuthUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIAuthUsecase) EXPECT() *MockIAuthUsecaseMockRecorder {
+	return m.recorder
+}
+
+// ForgotPassword mocks base method.
+func (m *MockIAuthUsecase) ForgotPassword(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForgotPassword", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForgotPassword indicates an expected call of ForgotPassword.
+func (mr *MockIAuthUsecaseMockRecorder) ForgotPassword(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForgotPassword", reflect.TypeOf((*MockIAuthUsecase)(nil).ForgotPassword), ctx, email)
+}
+
+// ResetPassword mocks base method.
+func (m *MockIAuthUsecase) ResetPassword(ctx context.Context, token, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, token, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockIAuthUsecaseMockRecorder) ResetPassword(ctx, token, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockIAuthUsecase)(nil).ResetPassword), ctx, token, password)
+}
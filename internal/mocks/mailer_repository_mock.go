@@ -48,3 +48,17 @@ func (mr *MockIMailerRepositoryMockRecorder) CheckEmailAvailability(ctx, email i
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckEmailAvailability", reflect.TypeOf((*MockIMailerRepository)(nil).CheckEmailAvailability), ctx, email)
 }
+
+// SendPasswordResetEmail mocks base method.
+func (m *MockIMailerRepository) SendPasswordResetEmail(ctx context.Context, email, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendPasswordResetEmail", ctx, email, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendPasswordResetEmail indicates an expected call of SendPasswordResetEmail.
+func (mr *MockIMailerRepositoryMockRecorder) SendPasswordResetEmail(ctx, email, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendPasswordResetEmail", reflect.TypeOf((*MockIMailerRepository)(nil).SendPasswordResetEmail), ctx, email, token)
+}
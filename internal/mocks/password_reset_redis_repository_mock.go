@@ -0,0 +1,80 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repositories/password_reset_redis_repository.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIPasswordResetRepository is a mock of IPasswordResetRepository interface.
+type MockIPasswordResetRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIPasswordResetRepositoryMockRecorder
+}
+
+// MockIPasswordResetRepositoryMockRecorder is the mock recorder for MockIPasswordResetRepository.
+type MockIPasswordResetRepositoryMockRecorder struct {
+	mock *MockIPasswordResetRepository
+}
+
+// NewMockIPasswordResetRepository creates a new mock instance.
+func NewMockIPasswordResetRepository(ctrl *gomock.Controller) *MockIPasswordResetRepository {
+	mock := &MockIPasswordResetRepository{ctrl: ctrl}
+	mock.recorder = &MockIPasswordResetRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIPasswordResetRepository) EXPECT() *MockIPasswordResetRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimToken mocks base method.
+func (m *MockIPasswordResetRepository) ClaimToken(ctx context.Context, token string) (uint, time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimToken", ctx, token)
+	ret0, _ := ret[0].(uint)
+	ret1, _ := ret[1].(time.Time)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ClaimToken indicates an expected call of ClaimToken.
+func (mr *MockIPasswordResetRepositoryMockRecorder) ClaimToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimToken", reflect.TypeOf((*MockIPasswordResetRepository)(nil).ClaimToken), ctx, token)
+}
+
+// RestoreToken mocks base method.
+func (m *MockIPasswordResetRepository) RestoreToken(ctx context.Context, token string, userID uint, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreToken", ctx, token, userID, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreToken indicates an expected call of RestoreToken.
+func (mr *MockIPasswordResetRepositoryMockRecorder) RestoreToken(ctx, token, userID, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreToken", reflect.TypeOf((*MockIPasswordResetRepository)(nil).RestoreToken), ctx, token, userID, expiresAt)
+}
+
+// SaveToken mocks base method.
+func (m *MockIPasswordResetRepository) SaveToken(ctx context.Context, token string, userID uint, expiration time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveToken", ctx, token, userID, expiration)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveToken indicates an expected call of SaveToken.
+func (mr *MockIPasswordResetRepositoryMockRecorder) SaveToken(ctx, token, userID, expiration interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveToken", reflect.TypeOf((*MockIPasswordResetRepository)(nil).SaveToken), ctx, token, userID, expiration)
+}
@@ -35,6 +35,20 @@ func (m *MockIUserRedisRepository) EXPECT() *MockIUserRedisRepositoryMockRecorde
 	return m.recorder
 }
 
+// DeleteUser mocks base method.
+func (m *MockIUserRedisRepository) DeleteUser(ctx context.Context, id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockIUserRedisRepositoryMockRecorder) DeleteUser(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockIUserRedisRepository)(nil).DeleteUser), ctx, id)
+}
+
 // GetUser mocks base method.
 func (m *MockIUserRedisRepository) GetUser(ctx context.Context, id uint) (*models.User, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repositories/user_session_redis_repository.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIUserSessionRepository is a mock of IUserSessionRepository interface.
+type MockIUserSessionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIUserSessionRepositoryMockRecorder
+}
+
+// MockIUserSessionRepositoryMockRecorder is the mock recorder for MockIUserSessionRepository.
+type MockIUserSessionRepositoryMockRecorder struct {
+	mock *MockIUserSessionRepository
+}
+
+// NewMockIUserSessionRepository creates a new mock instance.
+func NewMockIUserSessionRepository(ctrl *gomock.Controller) *MockIUserSessionRepository {
+	mock := &MockIUserSessionRepository{ctrl: ctrl}
+	mock.recorder = &MockIUserSessionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIUserSessionRepository) EXPECT() *MockIUserSessionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// RevokeAll mocks base method.
+func (m *MockIUserSessionRepository) RevokeAll(ctx context.Context, userID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAll", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAll indicates an expected call of RevokeAll.
+func (mr *MockIUserSessionRepositoryMockRecorder) RevokeAll(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAll", reflect.TypeOf((*MockIUserSessionRepository)(nil).RevokeAll), ctx, userID)
+}
+
+// RevokedAt mocks base method.
+func (m *MockIUserSessionRepository) RevokedAt(ctx context.Context, userID uint) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokedAt", ctx, userID)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokedAt indicates an expected call of RevokedAt.
+func (mr *MockIUserSessionRepositoryMockRecorder) RevokedAt(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokedAt", reflect.TypeOf((*MockIUserSessionRepository)(nil).RevokedAt), ctx, userID)
+}
@@ -0,0 +1,61 @@
+// Package module defines how a feature contributes its constructors, routes and middlewares
+// to the application, so adding a feature does not require editing the container or the app.
+package module
+
+import (
+	"sort"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Value groups the routes and middlewares of the modules are collected in.
+const (
+	GroupRoutes      = "routes"
+	GroupMiddlewares = "middlewares"
+)
+
+// Dependency registers a constructor in the container, as Interface under the name Token,
+// in the value group Group, or as the type it returns when both are empty.
+type Dependency struct {
+	Constructor interface{}
+	Interface   interface{}
+	Token       string
+	Group       string // Value group the constructor contributes to, such as "metrics".
+}
+
+// Module is a feature of the application.
+type Module struct {
+	Name         string
+	Dependencies []Dependency
+	// Routes is a constructor returning the Routes of the module. Its parameters are resolved
+	// by the container, typically a dig.In struct with the handlers of the module.
+	Routes interface{}
+	// Middlewares is a constructor returning the []Middleware of the module, applied to every route.
+	Middlewares interface{}
+}
+
+// Router is where modules mount their routes.
+type Router struct {
+	Echo *echo.Echo  // Root of the server.
+	V1   *echo.Group // The /api/v1 group.
+}
+
+// Routes mounts the routes of a module.
+type Routes func(r Router)
+
+// Middleware is a middleware applied to every route, after the middlewares of the server.
+type Middleware struct {
+	Name  string
+	Order int // Middlewares run by ascending Order, then by Name.
+	Func  echo.MiddlewareFunc
+}
+
+// SortMiddlewares sorts ms in the order they run. The container collects them in no particular order.
+func SortMiddlewares(ms []Middleware) {
+	sort.SliceStable(ms, func(i, j int) bool {
+		if ms[i].Order != ms[j].Order {
+			return ms[i].Order < ms[j].Order
+		}
+		return ms[i].Name < ms[j].Name
+	})
+}
@@ -0,0 +1,25 @@
+package module_test
+
+import (
+	"testing"
+
+	"github.com/nutsp/golang-clean-architecture/internal/module"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortMiddlewares(t *testing.T) {
+	ms := []module.Middleware{
+		{Name: "flags", Order: 10},
+		{Name: "tenant", Order: 0},
+		{Name: "audit", Order: 10},
+		{Name: "locale", Order: -5},
+	}
+
+	module.SortMiddlewares(ms)
+
+	var names []string
+	for _, m := range ms {
+		names = append(names, m.Name)
+	}
+	assert.Equal(t, []string{"locale", "tenant", "audit", "flags"}, names)
+}
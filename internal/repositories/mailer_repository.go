@@ -2,14 +2,21 @@ package repositories
 
 import (
 	"context"
-	"encoding/json"
+	"fmt"
+	"net/url"
 
 	httpClient "github.com/nutsp/golang-clean-architecture/pkg/httpclient"
+	"github.com/nutsp/golang-clean-architecture/pkg/observability"
 	"go.uber.org/dig"
 )
 
 type IMailerRepository interface {
 	CheckEmailAvailability(ctx context.Context, email string) (bool, error)
+	SendPasswordResetEmail(ctx context.Context, email string, token string) error
+}
+
+type emailAvailabilityResponse struct {
+	Available bool `json:"available"`
 }
 
 type MailerRepository struct {
@@ -18,7 +25,7 @@ type MailerRepository struct {
 
 type MailerRepositoryDependencies struct {
 	dig.In
-	Client httpClient.IClient `name:"HttpClient"`
+	Client httpClient.IClient `name:"MailerHttpClient"`
 }
 
 func NewMailerRepository(deps MailerRepositoryDependencies) *MailerRepository {
@@ -27,31 +34,47 @@ func NewMailerRepository(deps MailerRepositoryDependencies) *MailerRepository {
 	}
 }
 
-func (r *MailerRepository) CheckEmailAvailability(ctx context.Context, email string) (bool, error) {
+// CheckEmailAvailability reports whether no account uses the email yet. Only an explicit
+// "available": false answer means the email is taken, a failing mailer API is returned as error.
+func (r *MailerRepository) CheckEmailAvailability(ctx context.Context, email string) (available bool, err error) {
+	ctx, span := observability.StartSpan(ctx, "MailerRepository.CheckEmailAvailability")
+	defer func() { observability.EndSpan(span, err) }()
+
 	req := &httpClient.Request{
 		Method: httpClient.MethodGet,
-		URL:    "api.example.com/email-availability?email=" + email,
-		Header: httpClient.Header{
-			httpClient.ContentType: httpClient.ApplicationJson,
-		},
+		URL:    "/email-availability",
+		Query:  url.Values{"email": {email}},
 	}
 
-	resp, err := r.client.Do(ctx, req)
+	response, err := httpClient.DoJSON[emailAvailabilityResponse](ctx, r.client, req)
 	if err != nil {
 		return false, err
 	}
 
-	if !resp.IsSuccess() {
-		return false, nil
-	}
+	return response.Available, nil
+}
+
+func (r *MailerRepository) SendPasswordResetEmail(ctx context.Context, email string, token string) (err error) {
+	ctx, span := observability.StartSpan(ctx, "MailerRepository.SendPasswordResetEmail")
+	defer func() { observability.EndSpan(span, err) }()
 
-	var response struct {
-		Available bool `json:"available"`
+	req := &httpClient.Request{
+		Method: httpClient.MethodPost,
+		URL:    "/password-reset",
+		Body: map[string]string{
+			"email": email,
+			"token": token,
+		},
 	}
-	err = json.Unmarshal(resp.Body, &response)
+
+	resp, err := r.client.Do(ctx, req)
 	if err != nil {
-		return false, err
+		return err
 	}
 
-	return response.Available, nil
+	if !resp.IsSuccess() {
+		return fmt.Errorf("send password reset email: unexpected status %d", resp.StatusCode)
+	}
+
+	return nil
 }
@@ -0,0 +1,108 @@
+package repositories_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/nutsp/golang-clean-architecture/config"
+	"github.com/nutsp/golang-clean-architecture/internal/repositories"
+	httpClient "github.com/nutsp/golang-clean-architecture/pkg/httpclient"
+	"github.com/nutsp/golang-clean-architecture/pkg/httpclient/stub"
+	"github.com/stretchr/testify/suite"
+)
+
+// MailerRepositoryHTTPTestSuite runs MailerRepository against a stub of the mailer API
+// through a real httpclient.Client.
+type MailerRepositoryHTTPTestSuite struct {
+	suite.Suite
+	server           *stub.Server
+	mailerRepository *repositories.MailerRepository
+}
+
+func (s *MailerRepositoryHTTPTestSuite) SetupTest() {
+	s.server = stub.NewServer(s.T())
+
+	client, err := httpClient.NewServiceClient(config.HttpClient{
+		Services: map[string]config.HttpService{
+			"mailer": {BaseURL: s.server.URL},
+		},
+	}, "mailer")
+	s.Require().NoError(err)
+
+	s.mailerRepository = repositories.NewMailerRepository(repositories.MailerRepositoryDependencies{
+		Client: client,
+	})
+}
+
+func TestMailerRepositoryHTTPTestSuite(t *testing.T) {
+	suite.Run(t, new(MailerRepositoryHTTPTestSuite))
+}
+
+func (s *MailerRepositoryHTTPTestSuite) TestCheckEmailAvailability() {
+	ctx := context.Background()
+	email := "john+doe@example.com"
+
+	s.Run("success_case_email_is_available", func() {
+		s.server.Reset()
+		s.server.Stub(stub.Route{
+			Method:   http.MethodGet,
+			Path:     "/email-availability",
+			Query:    url.Values{"email": {email}},
+			Response: stub.Response{JSON: map[string]bool{"available": true}},
+		})
+
+		available, err := s.mailerRepository.CheckEmailAvailability(ctx, email)
+		s.NoError(err)
+		s.True(available)
+		s.Len(s.server.CallsTo(http.MethodGet, "/email-availability"), 1)
+	})
+
+	s.Run("fail_case_unexpected_status", func() {
+		s.server.Reset()
+		s.server.Stub(stub.Route{
+			Method:   http.MethodGet,
+			Path:     "/email-availability",
+			Response: stub.Response{Status: http.StatusServiceUnavailable},
+		})
+
+		_, err := s.mailerRepository.CheckEmailAvailability(ctx, email)
+		var statusErr *httpClient.StatusError
+		s.Require().ErrorAs(err, &statusErr)
+		s.Equal(http.StatusServiceUnavailable, statusErr.StatusCode)
+	})
+
+	s.Run("fail_case_email_is_taken", func() {
+		s.server.Reset()
+		s.server.Stub(stub.Route{
+			Method:   http.MethodGet,
+			Path:     "/email-availability",
+			Response: stub.Response{JSON: map[string]bool{"available": false}},
+		})
+
+		available, err := s.mailerRepository.CheckEmailAvailability(ctx, email)
+		s.NoError(err)
+		s.False(available)
+	})
+
+	s.Run("fail_case_connection_reset", func() {
+		s.server.Reset()
+		s.server.Stub(stub.Route{Path: "/email-availability", Fault: stub.ConnectionReset})
+
+		_, err := s.mailerRepository.CheckEmailAvailability(ctx, email)
+		s.Error(err)
+	})
+}
+
+func (s *MailerRepositoryHTTPTestSuite) TestSendPasswordResetEmail() {
+	s.server.Stub(stub.Route{Method: http.MethodPost, Path: "/password-reset"})
+
+	err := s.mailerRepository.SendPasswordResetEmail(context.Background(), "john@example.com", "token")
+	s.NoError(err)
+
+	calls := s.server.CallsTo(http.MethodPost, "/password-reset")
+	s.Require().Len(calls, 1)
+	s.JSONEq(`{"email":"john@example.com","token":"token"}`, string(calls[0].Body))
+	s.Equal(httpClient.ApplicationJson, calls[0].Header.Get(httpClient.ContentType))
+}
@@ -2,6 +2,7 @@ package repositories_test
 
 import (
 	"context"
+	"errors"
 	"io"
 	"net/http"
 	"strings"
@@ -84,4 +85,28 @@ func (s *MailerRepositoryTestSuite) TestMailerRepositoryCheckEmailAvailability()
 		s.NoError(err)
 		s.False(available)
 	})
+	s.Run("fail_case_unexpected_status", func() {
+		mockResponse := &httpClient.Response{
+			Response: &http.Response{
+				StatusCode: http.StatusServiceUnavailable,
+				Header:     make(http.Header),
+			},
+			Body: []byte(`service unavailable`),
+		}
+
+		s.mockClient.EXPECT().Do(ctx, gomock.Any()).Return(mockResponse, nil)
+
+		_, err := s.mailerRepository.CheckEmailAvailability(ctx, email)
+		var statusErr *httpClient.StatusError
+		s.Require().ErrorAs(err, &statusErr)
+		s.Equal(http.StatusServiceUnavailable, statusErr.StatusCode)
+	})
+
+	s.Run("fail_case_retries_exhausted", func() {
+		retryErr := &httpClient.RetryError{Attempts: 3, Err: errors.New("connection refused")}
+		s.mockClient.EXPECT().Do(ctx, gomock.Any()).Return(nil, retryErr)
+
+		_, err := s.mailerRepository.CheckEmailAvailability(ctx, email)
+		s.ErrorIs(err, retryErr)
+	})
 }
@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/nutsp/golang-clean-architecture/pkg/datasource"
+	"github.com/nutsp/golang-clean-architecture/pkg/observability"
+	"go.uber.org/dig"
+)
+
+type IPasswordResetRepository interface {
+	SaveToken(ctx context.Context, token string, userID uint, expiration time.Duration) error
+	ClaimToken(ctx context.Context, token string) (uint, time.Time, error)
+	RestoreToken(ctx context.Context, token string, userID uint, expiresAt time.Time) error
+}
+
+type PasswordResetRepository struct {
+	client datasource.IRedisClient
+}
+
+type PasswordResetRepositoryDependencies struct {
+	dig.In
+	Client datasource.IRedisClient `name:"RedisClient"`
+}
+
+func NewPasswordResetRepository(deps PasswordResetRepositoryDependencies) *PasswordResetRepository {
+	return &PasswordResetRepository{
+		client: deps.Client,
+	}
+}
+
+// SaveToken stores the token owner until the token expires.
+// Only a hash of the token is used as key, so the raw token never reaches Redis.
+func (r *PasswordResetRepository) SaveToken(ctx context.Context, token string, userID uint, expiration time.Duration) (err error) {
+	ctx, span := observability.StartSpan(ctx, "PasswordResetRepository.SaveToken")
+	defer func() { observability.EndSpan(span, err) }()
+
+	return r.save(ctx, token, userID, time.Now().Add(expiration))
+}
+
+// ClaimToken deletes the token and returns the user it was issued for and when it expires, or 0
+// when the token is unknown or expired. The token is read and deleted at once, so concurrent
+// resets can not both claim it.
+func (r *PasswordResetRepository) ClaimToken(ctx context.Context, token string) (userID uint, expiresAt time.Time, err error) {
+	ctx, span := observability.StartSpan(ctx, "PasswordResetRepository.ClaimToken")
+	defer func() { observability.EndSpan(span, err) }()
+
+	val, err := r.client.GetDel(ctx, r.keyName(token))
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return 0, time.Time{}, nil
+		}
+		return 0, time.Time{}, err
+	}
+
+	owner, expiry, _ := strings.Cut(val, ":")
+	id, idErr := strconv.ParseUint(owner, 10, 64)
+	ms, msErr := strconv.ParseInt(expiry, 10, 64)
+	if idErr != nil || msErr != nil {
+		return 0, time.Time{}, fmt.Errorf("password reset token: malformed value %q", val)
+	}
+
+	return uint(id), time.UnixMilli(ms), nil
+}
+
+// RestoreToken stores a claimed token again until its original expiry, when the reset it was
+// claimed for failed, so the user can retry with it.
+func (r *PasswordResetRepository) RestoreToken(ctx context.Context, token string, userID uint, expiresAt time.Time) (err error) {
+	ctx, span := observability.StartSpan(ctx, "PasswordResetRepository.RestoreToken")
+	defer func() { observability.EndSpan(span, err) }()
+
+	if !time.Now().Before(expiresAt) {
+		return nil
+	}
+	return r.save(ctx, token, userID, expiresAt)
+}
+
+// save stores "<user ID>:<expiry in unix milliseconds>", the expiry lets a claimed token be restored.
+func (r *PasswordResetRepository) save(ctx context.Context, token string, userID uint, expiresAt time.Time) error {
+	return r.client.Set(ctx, r.keyName(token), fmt.Sprintf("%d:%d", userID, expiresAt.UnixMilli()), time.Until(expiresAt))
+}
+
+func (r *PasswordResetRepository) keyName(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return r.client.GetKeyName("password_reset", hex.EncodeToString(sum[:]))
+}
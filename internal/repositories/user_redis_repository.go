@@ -10,12 +10,20 @@ import (
 	"github.com/go-redis/redis/v8"
 	"github.com/nutsp/golang-clean-architecture/internal/models"
 	"github.com/nutsp/golang-clean-architecture/pkg/datasource"
+	"github.com/nutsp/golang-clean-architecture/pkg/observability"
 	"go.uber.org/dig"
 )
 
+// userCacheSchemaVersion must be bumped whenever the JSON shape of models.User changes,
+// so that entries cached with the previous shape are not read back.
+const userCacheSchemaVersion = 1
+
+var userKeyPrefix = datasource.VersionedPrefix("users", userCacheSchemaVersion)
+
 type IUserRedisRepository interface {
 	SetUser(ctx context.Context, user *models.User) error
 	GetUser(ctx context.Context, id uint) (*models.User, error)
+	DeleteUser(ctx context.Context, id uint) error
 }
 
 type UserRedisRepository struct {
@@ -33,8 +41,11 @@ func NewUserRedisRepository(deps UserRedisRepositoryDependencies) *UserRedisRepo
 	}
 }
 
-func (r *UserRedisRepository) SetUser(ctx context.Context, user *models.User) error {
-	key := r.client.GetKeyName("users", fmt.Sprint(user.ID))
+func (r *UserRedisRepository) SetUser(ctx context.Context, user *models.User) (err error) {
+	ctx, span := observability.StartSpan(ctx, "UserRedisRepository.SetUser")
+	defer func() { observability.EndSpan(span, err) }()
+
+	key := r.client.GetKeyName(userKeyPrefix, fmt.Sprint(user.ID))
 	val, err := json.Marshal(user)
 	if err != nil {
 		return err
@@ -42,9 +53,11 @@ func (r *UserRedisRepository) SetUser(ctx context.Context, user *models.User) er
 	return r.client.Set(ctx, key, val, 15*time.Minute)
 }
 
-func (r *UserRedisRepository) GetUser(ctx context.Context, id uint) (*models.User, error) {
-	var user *models.User
-	key := r.client.GetKeyName("users", fmt.Sprint(id))
+func (r *UserRedisRepository) GetUser(ctx context.Context, id uint) (user *models.User, err error) {
+	ctx, span := observability.StartSpan(ctx, "UserRedisRepository.GetUser")
+	defer func() { observability.EndSpan(span, err) }()
+
+	key := r.client.GetKeyName(userKeyPrefix, fmt.Sprint(id))
 	userStr, err := r.client.Get(ctx, key)
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
@@ -59,3 +72,11 @@ func (r *UserRedisRepository) GetUser(ctx context.Context, id uint) (*models.Use
 
 	return user, nil
 }
+
+func (r *UserRedisRepository) DeleteUser(ctx context.Context, id uint) (err error) {
+	ctx, span := observability.StartSpan(ctx, "UserRedisRepository.DeleteUser")
+	defer func() { observability.EndSpan(span, err) }()
+
+	key := r.client.GetKeyName(userKeyPrefix, fmt.Sprint(id))
+	return r.client.Del(ctx, key)
+}
@@ -3,11 +3,14 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"errors"
 
 	"github.com/nutsp/golang-clean-architecture/internal/infastructure/database"
 	"github.com/nutsp/golang-clean-architecture/internal/models"
 	"github.com/nutsp/golang-clean-architecture/pkg/datasource"
+	"github.com/nutsp/golang-clean-architecture/pkg/observability"
 	"go.uber.org/dig"
+	"gorm.io/gorm"
 )
 
 type IUserRepository interface {
@@ -16,6 +19,8 @@ type IUserRepository interface {
 	UpdateByID(ctx context.Context, user *models.User) error
 	GetAll(ctx context.Context) ([]*models.User, error)
 	GetByID(ctx context.Context, id uint) (*models.User, error)
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	UpdatePassword(ctx context.Context, id uint, password string) error
 }
 
 type UserRepository struct {
@@ -53,29 +58,62 @@ func (r *UserRepository) Atomic(ctx context.Context, opt *sql.TxOptions, repo fu
 	return nil
 }
 
-func (r *UserRepository) Save(ctx context.Context, user *models.User) error {
+func (r *UserRepository) Save(ctx context.Context, user *models.User) (err error) {
+	ctx, span := observability.StartSpan(ctx, "UserRepository.Save")
+	defer func() { observability.EndSpan(span, err) }()
+
 	return r.conn.Debug().WithContext(ctx).Create(user).Error()
 }
 
-func (r *UserRepository) UpdateByID(ctx context.Context, user *models.User) error {
+func (r *UserRepository) UpdateByID(ctx context.Context, user *models.User) (err error) {
+	ctx, span := observability.StartSpan(ctx, "UserRepository.UpdateByID")
+	defer func() { observability.EndSpan(span, err) }()
+
 	return r.conn.Debug().WithContext(ctx).Where("id =?", user.ID).Updates(user).Error()
 }
 
-func (r *UserRepository) GetAll(ctx context.Context) ([]*models.User, error) {
-	var users []*models.User
-	err := r.conn.Debug().WithContext(ctx).Find(&users).Error()
+func (r *UserRepository) GetAll(ctx context.Context) (users []*models.User, err error) {
+	ctx, span := observability.StartSpan(ctx, "UserRepository.GetAll")
+	defer func() { observability.EndSpan(span, err) }()
+
+	err = r.conn.Debug().WithContext(ctx).Find(&users).Error()
 	if err != nil {
 		return nil, err
 	}
 	return users, err
 }
 
-func (r *UserRepository) GetByID(ctx context.Context, id uint) (*models.User, error) {
-	var user *models.User
-	err := r.conn.Debug().WithContext(ctx).Where("id =?", id).First(&user).Error()
+func (r *UserRepository) GetByID(ctx context.Context, id uint) (user *models.User, err error) {
+	ctx, span := observability.StartSpan(ctx, "UserRepository.GetByID")
+	defer func() { observability.EndSpan(span, err) }()
+
+	err = r.conn.Debug().WithContext(ctx).Where("id =?", id).First(&user).Error()
 	if err != nil {
 		return nil, err
 	}
 
 	return user, nil
 }
+
+// GetByEmail returns the user registered with the given email, or nil when there is none.
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (user *models.User, err error) {
+	ctx, span := observability.StartSpan(ctx, "UserRepository.GetByEmail")
+	defer func() { observability.EndSpan(span, err) }()
+
+	err = r.conn.Debug().WithContext(ctx).Where("email =?", email).First(&user).Error()
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (r *UserRepository) UpdatePassword(ctx context.Context, id uint, password string) (err error) {
+	ctx, span := observability.StartSpan(ctx, "UserRepository.UpdatePassword")
+	defer func() { observability.EndSpan(span, err) }()
+
+	return r.conn.Debug().WithContext(ctx).Where("id =?", id).Updates(&models.User{Password: password}).Error()
+}
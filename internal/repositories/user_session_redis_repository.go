@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/nutsp/golang-clean-architecture/config"
+	"github.com/nutsp/golang-clean-architecture/pkg/datasource"
+	"github.com/nutsp/golang-clean-architecture/pkg/observability"
+	"go.uber.org/dig"
+)
+
+type IUserSessionRepository interface {
+	RevokeAll(ctx context.Context, userID uint) error
+	RevokedAt(ctx context.Context, userID uint) (time.Time, error)
+}
+
+type UserSessionRepository struct {
+	client datasource.IRedisClient
+	ttl    time.Duration
+}
+
+type UserSessionRepositoryDependencies struct {
+	dig.In
+	Config *config.Config
+	Client datasource.IRedisClient `name:"RedisClient"`
+}
+
+func NewUserSessionRepository(deps UserSessionRepositoryDependencies) *UserSessionRepository {
+	return &UserSessionRepository{
+		client: deps.Client,
+		ttl:    time.Duration(deps.Config.JWT.Expired) * time.Second,
+	}
+}
+
+// RevokeAll records the second every session of the user was invalidated in.
+// Tokens issued before that second are rejected by SessionRevocationMiddleware. The record
+// is kept as long as a token lives, after that every token issued before it has expired anyway.
+func (r *UserSessionRepository) RevokeAll(ctx context.Context, userID uint) (err error) {
+	ctx, span := observability.StartSpan(ctx, "UserSessionRepository.RevokeAll")
+	defer func() { observability.EndSpan(span, err) }()
+
+	key := r.client.GetKeyName("sessions_revoked", fmt.Sprint(userID))
+	return r.client.Set(ctx, key, time.Now().Unix(), r.ttl)
+}
+
+// RevokedAt returns when the sessions of the user were last revoked, or the zero time if never.
+func (r *UserSessionRepository) RevokedAt(ctx context.Context, userID uint) (revokedAt time.Time, err error) {
+	ctx, span := observability.StartSpan(ctx, "UserSessionRepository.RevokedAt")
+	defer func() { observability.EndSpan(span, err) }()
+
+	key := r.client.GetKeyName("sessions_revoked", fmt.Sprint(userID))
+	val, err := r.client.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+
+	unix, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(unix, 0), nil
+}
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/nutsp/golang-clean-architecture/internal/repositories"
+	appError "github.com/nutsp/golang-clean-architecture/pkg/apperror"
+	"github.com/nutsp/golang-clean-architecture/pkg/lifecycle"
+	"github.com/nutsp/golang-clean-architecture/pkg/observability"
+	"go.uber.org/dig"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	passwordResetTokenBytes = 32
+	passwordResetTokenTTL   = 30 * time.Minute
+)
+
+type IAuthUsecase interface {
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token string, password string) error
+}
+
+type AuthUsecase struct {
+	logger                  observability.Logger
+	lifecycle               *lifecycle.Lifecycle
+	userRepository          repositories.IUserRepository
+	mailerRepository        repositories.IMailerRepository
+	userRedisRepository     repositories.IUserRedisRepository
+	passwordResetRepository repositories.IPasswordResetRepository
+	userSessionRepository   repositories.IUserSessionRepository
+}
+
+type AuthUsecaseDependencies struct {
+	dig.In
+	Logger                  observability.Logger                  `name:"Logger"`
+	UserRepository          repositories.IUserRepository          `name:"UserRepository"`
+	MailerRepository        repositories.IMailerRepository        `name:"MailerRepository"`
+	UserRedisRepository     repositories.IUserRedisRepository     `name:"UserRedisRepository"`
+	PasswordResetRepository repositories.IPasswordResetRepository `name:"PasswordResetRepository"`
+	UserSessionRepository   repositories.IUserSessionRepository   `name:"UserSessionRepository"`
+	Lifecycle               *lifecycle.Lifecycle
+}
+
+func NewAuthUsecase(deps AuthUsecaseDependencies) *AuthUsecase {
+	return &AuthUsecase{
+		logger:                  deps.Logger,
+		lifecycle:               deps.Lifecycle,
+		userRepository:          deps.UserRepository,
+		mailerRepository:        deps.MailerRepository,
+		userRedisRepository:     deps.UserRedisRepository,
+		passwordResetRepository: deps.PasswordResetRepository,
+		userSessionRepository:   deps.UserSessionRepository,
+	}
+}
+
+// ForgotPassword issues a single-use reset token and mails it to the user.
+// It succeeds whether or not the email is registered, so callers cannot probe for accounts.
+// The token is issued in the background, otherwise a registered email would answer
+// measurably later than an unknown one.
+func (s *AuthUsecase) ForgotPassword(ctx context.Context, email string) (err error) {
+	ctx, span := observability.StartSpan(ctx, "AuthUsecase.ForgotPassword")
+	defer func() { observability.EndSpan(span, err) }()
+
+	user, err := s.userRepository.GetByEmail(ctx, email)
+	if err != nil {
+		return appError.InternalServerError(err)
+	}
+
+	if user.IsNil() {
+		return nil
+	}
+
+	// Not canceled with the request, and Stop waits for it, so a reset requested right
+	// before a shutdown is still mailed.
+	sendCtx := context.WithoutCancel(ctx)
+	s.lifecycle.Go("ForgotPassword", func(context.Context) {
+		if err := s.sendResetToken(sendCtx, user.ID, user.Email); err != nil {
+			s.logger.WithContext(sendCtx).Error("ForgotPassword", "user_id", user.ID, "error", err)
+		}
+	})
+
+	return nil
+}
+
+// sendResetToken saves a new reset token for the user and mails it.
+func (s *AuthUsecase) sendResetToken(ctx context.Context, userID uint, email string) (err error) {
+	ctx, span := observability.StartSpan(ctx, "AuthUsecase.sendResetToken")
+	defer func() { observability.EndSpan(span, err) }()
+
+	token, err := generateResetToken()
+	if err != nil {
+		return err
+	}
+
+	if err := s.passwordResetRepository.SaveToken(ctx, token, userID, passwordResetTokenTTL); err != nil {
+		return err
+	}
+
+	return s.mailerRepository.SendPasswordResetEmail(ctx, email, token)
+}
+
+// ResetPassword replaces the password of the owner of the reset token. The token is claimed
+// first, so it can only be used once even by concurrent requests, and restored when the password
+// could not be updated, so the attempt can be retried with it.
+// Existing sessions are revoked and the cached user is evicted.
+func (s *AuthUsecase) ResetPassword(ctx context.Context, token string, password string) (err error) {
+	ctx, span := observability.StartSpan(ctx, "AuthUsecase.ResetPassword")
+	defer func() { observability.EndSpan(span, err) }()
+
+	userID, expiresAt, err := s.passwordResetRepository.ClaimToken(ctx, token)
+	if err != nil {
+		return appError.InternalServerError(err)
+	}
+
+	if userID == 0 {
+		return appError.BadRequest(appError.ErrInvalidResetToken)
+	}
+
+	// Hash the new password the same way CreateUser does
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err == nil {
+		err = s.userRepository.UpdatePassword(ctx, userID, string(hashedPassword))
+	}
+	if err != nil {
+		if restoreErr := s.passwordResetRepository.RestoreToken(ctx, token, userID, expiresAt); restoreErr != nil {
+			s.logger.WithContext(ctx).Error("ResetPassword", "user_id", userID, "error", restoreErr)
+		}
+		return appError.InternalServerError(err)
+	}
+
+	if err := s.userSessionRepository.RevokeAll(ctx, userID); err != nil {
+		return appError.InternalServerError(err)
+	}
+
+	if err := s.userRedisRepository.DeleteUser(ctx, userID); err != nil {
+		return appError.InternalServerError(err)
+	}
+
+	return nil
+}
+
+func generateResetToken() (string, error) {
+	b := make([]byte, passwordResetTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,240 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/nutsp/golang-clean-architecture/config"
+	"github.com/nutsp/golang-clean-architecture/internal/mocks"
+	"github.com/nutsp/golang-clean-architecture/internal/models"
+	"github.com/nutsp/golang-clean-architecture/internal/repositories"
+	"github.com/nutsp/golang-clean-architecture/internal/usecase"
+	appError "github.com/nutsp/golang-clean-architecture/pkg/apperror"
+	"github.com/nutsp/golang-clean-architecture/pkg/datasource"
+	"github.com/nutsp/golang-clean-architecture/pkg/lifecycle"
+	"github.com/nutsp/golang-clean-architecture/pkg/observability"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/codes"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type AuthUsecaseTestSuite struct {
+	suite.Suite
+	ctrl                  *gomock.Controller
+	mockUserRepo          *mocks.MockIUserRepository
+	mockMailerRepo        *mocks.MockIMailerRepository
+	mockUserRedisRepo     *mocks.MockIUserRedisRepository
+	mockPasswordResetRepo *mocks.MockIPasswordResetRepository
+	mockUserSessionRepo   *mocks.MockIUserSessionRepository
+	lifecycle             *lifecycle.Lifecycle
+	authUsecase           *usecase.AuthUsecase
+}
+
+func (s *AuthUsecaseTestSuite) SetupTest() {
+	s.ctrl = gomock.NewController(s.T())
+
+	s.mockUserRepo = mocks.NewMockIUserRepository(s.ctrl)
+	s.mockMailerRepo = mocks.NewMockIMailerRepository(s.ctrl)
+	s.mockUserRedisRepo = mocks.NewMockIUserRedisRepository(s.ctrl)
+	s.mockPasswordResetRepo = mocks.NewMockIPasswordResetRepository(s.ctrl)
+	s.mockUserSessionRepo = mocks.NewMockIUserSessionRepository(s.ctrl)
+	logger := observability.NewZapLogger(config.Logger{})
+	s.lifecycle = lifecycle.New(logger)
+
+	authDeps := usecase.AuthUsecaseDependencies{
+		Logger:                  logger,
+		Lifecycle:               s.lifecycle,
+		UserRepository:          s.mockUserRepo,
+		MailerRepository:        s.mockMailerRepo,
+		UserRedisRepository:     s.mockUserRedisRepo,
+		PasswordResetRepository: s.mockPasswordResetRepo,
+		UserSessionRepository:   s.mockUserSessionRepo,
+	}
+
+	s.authUsecase = usecase.NewAuthUsecase(authDeps)
+}
+
+func (s *AuthUsecaseTestSuite) TearDownTest() {
+	s.ctrl.Finish()
+}
+
+func TestAuthUsecaseTestSuite(t *testing.T) {
+	suite.Run(t, new(AuthUsecaseTestSuite))
+}
+
+func (s *AuthUsecaseTestSuite) TestForgotPassword() {
+	ctx := context.Background()
+	user := &models.User{ID: 1, Email: "test@example.com"}
+
+	s.Run("success_case_token_sent", func() {
+		var savedToken string
+		s.mockUserRepo.EXPECT().GetByEmail(gomock.Any(), user.Email).Return(user, nil)
+		s.mockPasswordResetRepo.EXPECT().SaveToken(gomock.Any(), gomock.Any(), user.ID, 30*time.Minute).
+			DoAndReturn(func(_ context.Context, token string, _ uint, _ time.Duration) error {
+				savedToken = token
+				return nil
+			})
+		s.mockMailerRepo.EXPECT().SendPasswordResetEmail(gomock.Any(), user.Email, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, token string) error {
+				assert.Equal(s.T(), savedToken, token)
+				return nil
+			})
+
+		err := s.authUsecase.ForgotPassword(ctx, user.Email)
+		s.NoError(err)
+
+		// The token is sent in the background, Stop waits for it.
+		s.Require().NoError(s.lifecycle.Stop(ctx))
+		s.Len(savedToken, 64)
+	})
+
+	s.Run("success_case_unknown_email", func() {
+		s.mockUserRepo.EXPECT().GetByEmail(gomock.Any(), "unknown@example.com").Return(nil, nil)
+
+		err := s.authUsecase.ForgotPassword(ctx, "unknown@example.com")
+		s.NoError(err)
+		s.Require().NoError(s.lifecycle.Stop(ctx))
+	})
+
+	s.Run("success_case_mailer_failure_is_hidden", func() {
+		s.mockUserRepo.EXPECT().GetByEmail(gomock.Any(), user.Email).Return(user, nil)
+		s.mockPasswordResetRepo.EXPECT().SaveToken(gomock.Any(), gomock.Any(), user.ID, gomock.Any()).Return(nil)
+		s.mockMailerRepo.EXPECT().SendPasswordResetEmail(gomock.Any(), user.Email, gomock.Any()).Return(errors.New("mailer down"))
+
+		err := s.authUsecase.ForgotPassword(ctx, user.Email)
+		s.NoError(err)
+		s.Require().NoError(s.lifecycle.Stop(ctx))
+	})
+
+	s.Run("success_case_token_is_sent_after_the_request_is_canceled", func() {
+		reqCtx, cancel := context.WithCancel(ctx)
+		s.mockUserRepo.EXPECT().GetByEmail(gomock.Any(), user.Email).Return(user, nil)
+		s.mockPasswordResetRepo.EXPECT().SaveToken(gomock.Any(), gomock.Any(), user.ID, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, _ string, _ uint, _ time.Duration) error {
+				return ctx.Err()
+			})
+		s.mockMailerRepo.EXPECT().SendPasswordResetEmail(gomock.Any(), user.Email, gomock.Any()).Return(nil)
+
+		err := s.authUsecase.ForgotPassword(reqCtx, user.Email)
+		cancel()
+		s.NoError(err)
+		s.Require().NoError(s.lifecycle.Stop(ctx))
+	})
+}
+
+func (s *AuthUsecaseTestSuite) TestResetPassword() {
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Minute)
+
+	s.Run("success_case_password_reset", func() {
+		s.mockPasswordResetRepo.EXPECT().ClaimToken(ctx, "token").Return(uint(1), expiresAt, nil)
+		s.mockUserRepo.EXPECT().UpdatePassword(ctx, uint(1), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ uint, hashed string) error {
+				assert.NoError(s.T(), bcrypt.CompareHashAndPassword([]byte(hashed), []byte("new-password")))
+				return nil
+			})
+		s.mockUserSessionRepo.EXPECT().RevokeAll(ctx, uint(1)).Return(nil)
+		s.mockUserRedisRepo.EXPECT().DeleteUser(ctx, uint(1)).Return(nil)
+
+		err := s.authUsecase.ResetPassword(ctx, "token", "new-password")
+		s.NoError(err)
+	})
+
+	s.Run("failure_case_invalid_token", func() {
+		s.mockPasswordResetRepo.EXPECT().ClaimToken(ctx, "expired").Return(uint(0), time.Time{}, nil)
+
+		err := s.authUsecase.ResetPassword(ctx, "expired", "new-password")
+		s.EqualError(err, appError.ErrInvalidResetToken.Error())
+	})
+
+	s.Run("failure_case_update_password_error", func() {
+		s.mockPasswordResetRepo.EXPECT().ClaimToken(ctx, "token").Return(uint(1), expiresAt, nil)
+		s.mockUserRepo.EXPECT().UpdatePassword(ctx, uint(1), gomock.Any()).Return(errors.New("update error"))
+		// The token is restored, so the user can retry with it.
+		s.mockPasswordResetRepo.EXPECT().RestoreToken(ctx, "token", uint(1), expiresAt).Return(nil)
+
+		err := s.authUsecase.ResetPassword(ctx, "token", "new-password")
+		s.EqualError(err, "update error")
+	})
+}
+
+func (s *AuthUsecaseTestSuite) TestResetPasswordConcurrently() {
+	ctx := context.Background()
+	redisClient := datasource.NewMemoryRedisClient()
+	resets := repositories.NewPasswordResetRepository(repositories.PasswordResetRepositoryDependencies{Client: redisClient})
+	authUsecase := usecase.NewAuthUsecase(usecase.AuthUsecaseDependencies{
+		Logger:                  observability.NewZapLogger(config.Logger{}),
+		UserRepository:          s.mockUserRepo,
+		UserRedisRepository:     s.mockUserRedisRepo,
+		PasswordResetRepository: resets,
+		UserSessionRepository:   s.mockUserSessionRepo,
+	})
+	s.Require().NoError(resets.SaveToken(ctx, "token", 1, time.Minute))
+
+	// Only one of the resets claims the token and changes the password.
+	s.mockUserRepo.EXPECT().UpdatePassword(ctx, uint(1), gomock.Any()).Return(nil).Times(1)
+	s.mockUserSessionRepo.EXPECT().RevokeAll(ctx, uint(1)).Return(nil).Times(1)
+	s.mockUserRedisRepo.EXPECT().DeleteUser(ctx, uint(1)).Return(nil).Times(1)
+
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		go func() { errs <- authUsecase.ResetPassword(ctx, "token", "new-password") }()
+	}
+
+	var succeeded int
+	for i := 0; i < 5; i++ {
+		if err := <-errs; err == nil {
+			succeeded++
+		} else {
+			s.EqualError(err, appError.ErrInvalidResetToken.Error())
+		}
+	}
+	s.Equal(1, succeeded)
+}
+
+func (s *AuthUsecaseTestSuite) TestResetPasswordRestoresToken() {
+	ctx := context.Background()
+	redisClient := datasource.NewMemoryRedisClient()
+	resets := repositories.NewPasswordResetRepository(repositories.PasswordResetRepositoryDependencies{Client: redisClient})
+	authUsecase := usecase.NewAuthUsecase(usecase.AuthUsecaseDependencies{
+		Logger:                  observability.NewZapLogger(config.Logger{}),
+		UserRepository:          s.mockUserRepo,
+		UserRedisRepository:     s.mockUserRedisRepo,
+		PasswordResetRepository: resets,
+		UserSessionRepository:   s.mockUserSessionRepo,
+	})
+	s.Require().NoError(resets.SaveToken(ctx, "token", 1, time.Minute))
+
+	s.mockUserRepo.EXPECT().UpdatePassword(ctx, uint(1), gomock.Any()).Return(errors.New("update error"))
+	s.EqualError(authUsecase.ResetPassword(ctx, "token", "new-password"), "update error")
+
+	// The failed attempt gave the token back, the retry succeeds with it.
+	s.mockUserRepo.EXPECT().UpdatePassword(ctx, uint(1), gomock.Any()).Return(nil)
+	s.mockUserSessionRepo.EXPECT().RevokeAll(ctx, uint(1)).Return(nil)
+	s.mockUserRedisRepo.EXPECT().DeleteUser(ctx, uint(1)).Return(nil)
+	s.NoError(authUsecase.ResetPassword(ctx, "token", "new-password"))
+
+	s.EqualError(authUsecase.ResetPassword(ctx, "token", "new-password"), appError.ErrInvalidResetToken.Error())
+}
+
+func (s *AuthUsecaseTestSuite) TestResetPasswordRecordsSpanError() {
+	tp, err := observability.NewTracerProvider(config.AppConfig{Name: "test"}, config.ObservabilityConfig{
+		Enable: true,
+		Mode:   observability.TracingModeMemory,
+	})
+	s.Require().NoError(err)
+	defer tp.Shutdown(context.Background())
+
+	s.mockPasswordResetRepo.EXPECT().ClaimToken(gomock.Any(), "token").Return(uint(0), time.Time{}, errors.New("connection refused"))
+
+	s.Error(s.authUsecase.ResetPassword(context.Background(), "token", "password"))
+
+	spans := tp.Spans()
+	s.Require().Len(spans, 1)
+	s.Equal("AuthUsecase.ResetPassword", spans[0].Name)
+	s.Equal(codes.Error, spans[0].Status.Code)
+}
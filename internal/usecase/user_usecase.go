@@ -8,11 +8,20 @@ import (
 	"github.com/nutsp/golang-clean-architecture/internal/models"
 	"github.com/nutsp/golang-clean-architecture/internal/repositories"
 	appError "github.com/nutsp/golang-clean-architecture/pkg/apperror"
+	"github.com/nutsp/golang-clean-architecture/pkg/featureflag"
+	httpClient "github.com/nutsp/golang-clean-architecture/pkg/httpclient"
+	"github.com/nutsp/golang-clean-architecture/pkg/metrics"
 	"github.com/nutsp/golang-clean-architecture/pkg/observability"
 	"go.uber.org/dig"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// userCacheName labels the user cache in the cache metrics.
+const userCacheName = "users"
+
+// FeatureSkipEmailCheck creates users without asking the mailer whether their email is available.
+const FeatureSkipEmailCheck = "skip-email-availability-check"
+
 type IUserUsecase interface {
 	CreateUser(ctx context.Context, user *models.User) error
 	UpdateUserInfo(ctx context.Context, user *models.User) error
@@ -24,6 +33,8 @@ type UserUsecase struct {
 	userRepository      repositories.IUserRepository
 	mailerRepository    repositories.IMailerRepository
 	userRedisRepository repositories.IUserRedisRepository
+	cacheMetrics        *metrics.CacheMetrics
+	features            featureflag.Flags
 }
 
 type UserUsecaseDependencies struct {
@@ -32,6 +43,8 @@ type UserUsecaseDependencies struct {
 	UserRepository      repositories.IUserRepository      `name:"UserRepository"`
 	MailerRepository    repositories.IMailerRepository    `name:"MailerRepository"`
 	UserRedisRepository repositories.IUserRedisRepository `name:"UserRedisRepository"`
+	CacheMetrics        *metrics.CacheMetrics
+	Features            featureflag.Flags `name:"FeatureFlags"`
 }
 
 func NewUserUsecase(deps UserUsecaseDependencies) *UserUsecase {
@@ -40,20 +53,32 @@ func NewUserUsecase(deps UserUsecaseDependencies) *UserUsecase {
 		userRepository:      deps.UserRepository,
 		mailerRepository:    deps.MailerRepository,
 		userRedisRepository: deps.UserRedisRepository,
+		cacheMetrics:        deps.CacheMetrics,
+		features:            deps.Features,
 	}
 }
 
 // CreateUser method creates a new user in the database.
-// It checks for email availability and hashes the password before saving the user.
-func (s *UserUsecase) CreateUser(ctx context.Context, user *models.User) error {
-	// Call the third-party API to check email availability
-	emailAvailable, err := s.mailerRepository.CheckEmailAvailability(ctx, user.Email)
-	if err != nil {
-		return appError.InternalServerError(err)
-	}
+// It checks for email availability, unless FeatureSkipEmailCheck is on, and hashes the password
+// before saving the user.
+func (s *UserUsecase) CreateUser(ctx context.Context, user *models.User) (err error) {
+	ctx, span := observability.StartSpan(ctx, "UserUsecase.CreateUser")
+	defer func() { observability.EndSpan(span, err) }()
+
+	// Without feature flags, every feature is off.
+	if s.features == nil || !s.features.Enabled(ctx, FeatureSkipEmailCheck) {
+		// Call the third-party API to check email availability
+		emailAvailable, err := s.mailerRepository.CheckEmailAvailability(ctx, user.Email)
+		if err != nil {
+			if errors.Is(err, httpClient.ErrCircuitOpen) || errors.Is(err, context.DeadlineExceeded) {
+				return appError.GatewayTimeout(err)
+			}
+			return appError.BadGateway(err)
+		}
 
-	if !emailAvailable {
-		return appError.InternalServerError(errors.New("email is already in use"))
+		if !emailAvailable {
+			return appError.InternalServerError(errors.New("email is already in use"))
+		}
 	}
 
 	// Hash the user's password
@@ -74,8 +99,11 @@ func (s *UserUsecase) CreateUser(ctx context.Context, user *models.User) error {
 	return nil
 }
 
-func (s *UserUsecase) UpdateUserInfo(ctx context.Context, user *models.User) error {
-	err := s.userRepository.Atomic(ctx, nil, func(tx repositories.IUserRepository) error {
+func (s *UserUsecase) UpdateUserInfo(ctx context.Context, user *models.User) (err error) {
+	ctx, span := observability.StartSpan(ctx, "UserUsecase.UpdateUserInfo")
+	defer func() { observability.EndSpan(span, err) }()
+
+	err = s.userRepository.Atomic(ctx, nil, func(tx repositories.IUserRepository) error {
 		users, err := tx.GetAll(ctx)
 		if err != nil {
 			return err
@@ -95,20 +123,26 @@ func (s *UserUsecase) UpdateUserInfo(ctx context.Context, user *models.User) err
 	return err
 }
 
-func (s *UserUsecase) GetUserInfo(ctx context.Context, id uint) (*models.User, error) {
-	user, err := s.userRedisRepository.GetUser(ctx, id)
+func (s *UserUsecase) GetUserInfo(ctx context.Context, id uint) (user *models.User, err error) {
+	ctx, span := observability.StartSpan(ctx, "UserUsecase.GetUserInfo")
+	defer func() { observability.EndSpan(span, err) }()
+
+	user, err = s.userRedisRepository.GetUser(ctx, id)
 	if err != nil {
 		return nil, appError.InternalServerError(err)
 	}
 
 	if user.IsNil() {
+		s.cacheMetrics.Miss(userCacheName)
+
 		user, err := s.userRepository.GetByID(ctx, id)
 		if err != nil {
 			return nil, appError.InternalServerError(err)
 		}
-		s.logger.Info("GetUserInfo", "user", user)
+		s.logger.WithContext(ctx).Info("GetUserInfo", "user", user)
 		return user, nil
 	}
-	s.logger.Info("GetUserInfo", "user", user)
+	s.cacheMetrics.Hit(userCacheName)
+	s.logger.WithContext(ctx).Info("GetUserInfo", "user", user)
 	return user, nil
 }
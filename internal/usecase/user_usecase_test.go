@@ -3,12 +3,21 @@ package usecase_test
 import (
 	"context"
 	"errors"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	"github.com/nutsp/golang-clean-architecture/config"
 	"github.com/nutsp/golang-clean-architecture/internal/mocks"
 	"github.com/nutsp/golang-clean-architecture/internal/models"
 	"github.com/nutsp/golang-clean-architecture/internal/usecase"
+	appError "github.com/nutsp/golang-clean-architecture/pkg/apperror"
+	"github.com/nutsp/golang-clean-architecture/pkg/featureflag"
+	httpClient "github.com/nutsp/golang-clean-architecture/pkg/httpclient"
+	"github.com/nutsp/golang-clean-architecture/pkg/metrics"
+	"github.com/nutsp/golang-clean-architecture/pkg/observability"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
@@ -19,6 +28,7 @@ type UserServiceTestSuite struct {
 	mockUserRepo      *mocks.MockIUserRepository
 	mockMailerRepo    *mocks.MockIMailerRepository
 	mockUserRedisRepo *mocks.MockIUserRedisRepository
+	flags             *featureflag.MemoryProvider
 	userService       *usecase.UserUsecase
 }
 
@@ -28,11 +38,13 @@ func (s *UserServiceTestSuite) SetupTest() {
 	s.mockUserRepo = mocks.NewMockIUserRepository(s.ctrl)
 	s.mockMailerRepo = mocks.NewMockIMailerRepository(s.ctrl)
 	s.mockUserRedisRepo = mocks.NewMockIUserRedisRepository(s.ctrl)
+	s.flags = featureflag.NewMemoryProvider(nil)
 
 	userDeps := usecase.UserUsecaseDependencies{
 		UserRepository:      s.mockUserRepo,
 		MailerRepository:    s.mockMailerRepo,
 		UserRedisRepository: s.mockUserRedisRepo,
+		Features:            featureflag.NewService(observability.NewZapLogger(config.Logger{}), s.flags),
 	}
 
 	s.userService = usecase.NewUserUsecase(userDeps)
@@ -99,6 +111,108 @@ func (s *UserServiceTestSuite) TestCreateUser() {
 		})
 	}
 }
+
+func (s *UserServiceTestSuite) TestCreateUserSkipEmailCheck() {
+	s.flags.Set(map[string]featureflag.Flag{usecase.FeatureSkipEmailCheck: {Users: []string{"42"}}})
+
+	// The flag is on for user 42 only, the mailer is not called for them.
+	user := &models.User{Email: "test@example.com", Password: "password"}
+	ctx := featureflag.WithUser(context.Background(), "42")
+	s.mockUserRepo.EXPECT().Save(ctx, user).Return(nil)
+	s.mockUserRedisRepo.EXPECT().SetUser(ctx, user).Return(nil)
+
+	s.NoError(s.userService.CreateUser(ctx, user))
+
+	user = &models.User{Email: "other@example.com", Password: "password"}
+	ctx = featureflag.WithUser(context.Background(), "7")
+	s.mockMailerRepo.EXPECT().CheckEmailAvailability(ctx, user.Email).Return(false, nil)
+
+	s.EqualError(s.userService.CreateUser(ctx, user), "email is already in use")
+}
+
+func (s *UserServiceTestSuite) TestCreateUserWithoutFeatureFlags() {
+	userService := usecase.NewUserUsecase(usecase.UserUsecaseDependencies{
+		UserRepository:   s.mockUserRepo,
+		MailerRepository: s.mockMailerRepo,
+	})
+	user := &models.User{Email: "test@example.com", Password: "password"}
+	ctx := context.Background()
+
+	// Every feature is off, the email is checked.
+	s.mockMailerRepo.EXPECT().CheckEmailAvailability(ctx, user.Email).Return(false, nil)
+
+	s.EqualError(userService.CreateUser(ctx, user), "email is already in use")
+}
+
+func (s *UserServiceTestSuite) TestCreateUserMailerCircuitOpen() {
+	user := &models.User{Email: "test@example.com", Password: "password"}
+	ctx := context.Background()
+
+	s.mockMailerRepo.EXPECT().CheckEmailAvailability(ctx, user.Email).
+		Return(false, &httpClient.CircuitOpenError{Host: "api.example.com"})
+
+	err := s.userService.CreateUser(ctx, user)
+
+	var appErr *appError.AppError
+	s.Require().ErrorAs(err, &appErr)
+	s.Equal(http.StatusGatewayTimeout, appErr.Code)
+}
+
+func (s *UserServiceTestSuite) TestCreateUserMailerFailure() {
+	user := &models.User{Email: "test@example.com", Password: "password"}
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode int
+	}{
+		{name: "unexpected status", err: &httpClient.StatusError{StatusCode: http.StatusServiceUnavailable}, expectedCode: http.StatusBadGateway},
+		{name: "retries exhausted", err: &httpClient.RetryError{Attempts: 3, Err: errors.New("connection refused")}, expectedCode: http.StatusBadGateway},
+		{name: "timeout", err: &httpClient.RetryError{Attempts: 3, Err: context.DeadlineExceeded}, expectedCode: http.StatusGatewayTimeout},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			s.mockMailerRepo.EXPECT().CheckEmailAvailability(ctx, user.Email).Return(false, tt.err)
+
+			err := s.userService.CreateUser(ctx, user)
+
+			var appErr *appError.AppError
+			s.Require().ErrorAs(err, &appErr)
+			s.Equal(tt.expectedCode, appErr.Code)
+		})
+	}
+}
+
+func (s *UserServiceTestSuite) TestGetUserInfoCacheMetrics() {
+	cacheMetrics := metrics.NewCacheMetrics()
+	userService := usecase.NewUserUsecase(usecase.UserUsecaseDependencies{
+		Logger:              observability.NewZapLogger(config.Logger{}),
+		UserRepository:      s.mockUserRepo,
+		UserRedisRepository: s.mockUserRedisRepo,
+		CacheMetrics:        cacheMetrics,
+	})
+	ctx := context.Background()
+	user := &models.User{ID: 1, Name: "John Doe"}
+
+	s.mockUserRedisRepo.EXPECT().GetUser(ctx, uint(1)).Return(user, nil)
+	s.mockUserRedisRepo.EXPECT().GetUser(ctx, uint(2)).Return(nil, nil)
+	s.mockUserRepo.EXPECT().GetByID(ctx, uint(2)).Return(&models.User{ID: 2}, nil)
+
+	_, err := userService.GetUserInfo(ctx, 1)
+	s.Require().NoError(err)
+	_, err = userService.GetUserInfo(ctx, 2)
+	s.Require().NoError(err)
+
+	s.NoError(testutil.CollectAndCompare(cacheMetrics, strings.NewReader(`
+# HELP cache_requests_total Number of cache lookups, by cache and result (hit or miss).
+# TYPE cache_requests_total counter
+cache_requests_total{cache="users",result="hit"} 1
+cache_requests_total{cache="users",result="miss"} 1
+`)))
+}
+
 func BenchmarkCreateUser(b *testing.B) {
 	ctrl := gomock.NewController(b)
 	defer ctrl.Finish()
@@ -109,6 +223,7 @@ func BenchmarkCreateUser(b *testing.B) {
 	userDeps := usecase.UserUsecaseDependencies{
 		UserRepository:   mockUserRepo,
 		MailerRepository: mockMailerRepo,
+		Features:         featureflag.NewService(observability.NewZapLogger(config.Logger{})),
 	}
 
 	userService := usecase.NewUserUsecase(userDeps)
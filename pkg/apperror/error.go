@@ -13,6 +13,13 @@ var (
 	ErrFailedGenerateJWT = errors.New("failed generate access token")
 	ErrInvalidIsActive   = errors.New("invalid is_active")
 	ErrStatusValue       = errors.New("status should be 0 or 1")
+	ErrInvalidResetToken = errors.New("invalid or expired reset token")
+	ErrRateLimitExceeded = errors.New("rate limit exceeded")
+	ErrBodyTooLarge      = errors.New("request body too large")
+
+	ErrInvalidIdempotencyKey    = errors.New("invalid idempotency key")
+	ErrIdempotencyKeyReused     = errors.New("idempotency key was already used with a different request")
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is in progress")
 
 	ErrFailedGetTokenInformation = errors.New("failed to get token information")
 )
@@ -79,6 +86,38 @@ func Conflict(err error) error {
 	}
 }
 
+func UnprocessableEntity(err error) error {
+	return &AppError{
+		Code:    http.StatusUnprocessableEntity,
+		Message: "unprocessable_entity",
+		Err:     err,
+	}
+}
+
+func RequestEntityTooLarge(err error) error {
+	return &AppError{
+		Code:    http.StatusRequestEntityTooLarge,
+		Message: "request_entity_too_large",
+		Err:     err,
+	}
+}
+
+func TooManyRequests(err error) error {
+	return &AppError{
+		Code:    http.StatusTooManyRequests,
+		Message: "too_many_requests",
+		Err:     err,
+	}
+}
+
+func BadGateway(err error) error {
+	return &AppError{
+		Code:    http.StatusBadGateway,
+		Message: "bad_gateway",
+		Err:     err,
+	}
+}
+
 func GatewayTimeout(err error) error {
 	return &AppError{
 		Code:    http.StatusGatewayTimeout,
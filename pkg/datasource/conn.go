@@ -15,7 +15,7 @@ import (
 
 func NewDatabase(cfg config.DatabaseConfig) (*gorm.DB, error) {
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.Name)
+		cfg.User, cfg.Password.Value(), cfg.Host, cfg.Port, cfg.Name)
 	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
 		Logger: logger.New(
 			log.New(os.Stdout, "\r\n", log.LstdFlags),
@@ -32,5 +32,9 @@ func NewDatabase(cfg config.DatabaseConfig) (*gorm.DB, error) {
 		return nil, err
 	}
 
+	if err := db.Use(GormTracing{}); err != nil {
+		return nil, err
+	}
+
 	return db, nil
 }
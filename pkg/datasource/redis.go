@@ -2,7 +2,12 @@ package datasource
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"os"
+	"sync"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -10,39 +15,393 @@ import (
 )
 
 type IRedisClient interface {
+	// GetKeyName returns "<namespace>:<prefix>:<key>".
 	GetKeyName(prefix string, key string) string
+	// NamespacePattern returns the SCAN pattern matching every key of the namespace.
+	NamespacePattern() string
+	// Ping checks that the server answers.
+	Ping(ctx context.Context) error
 	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error)
 	Get(ctx context.Context, key string) (string, error)
+	GetDel(ctx context.Context, key string) (string, error)
 	Del(ctx context.Context, keys ...string) error
+	Expire(ctx context.Context, key string, expiration time.Duration) (bool, error)
+	Incr(ctx context.Context, key string) (int64, error)
+
+	// MGet returns the value of every key, nil for the keys that do not exist.
+	MGet(ctx context.Context, keys ...string) ([]interface{}, error)
+	MSet(ctx context.Context, values map[string]interface{}) error
+
+	HSet(ctx context.Context, key string, values map[string]interface{}) error
+	HGet(ctx context.Context, key string, field string) (string, error)
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+	HDel(ctx context.Context, key string, fields ...string) error
+
+	// Scan calls fn with batches of keys matching the glob pattern.
+	Scan(ctx context.Context, match string, fn func(keys []string) error) error
+
+	// Pipeline sends the commands queued by fn in a single round trip.
+	Pipeline(ctx context.Context, fn func(pipe Pipeliner) error) error
+	// TxPipeline is like Pipeline but runs the commands atomically in MULTI/EXEC.
+	TxPipeline(ctx context.Context, fn func(pipe Pipeliner) error) error
+
+	RunScript(ctx context.Context, script *Script, keys []string, args ...interface{}) (interface{}, error)
+
+	Publish(ctx context.Context, channel string, message interface{}) error
+	Subscribe(ctx context.Context, channels ...string) (Subscription, error)
+
+	// Close releases the connections of the client.
+	Close() error
+}
+
+// Pipeliner queues commands of a pipeline. Results are available once the pipeline has run.
+type Pipeliner interface {
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration)
+	Get(ctx context.Context, key string) StringResult
+	Del(ctx context.Context, keys ...string)
+	Expire(ctx context.Context, key string, expiration time.Duration)
+	Incr(ctx context.Context, key string) IntResult
+	HSet(ctx context.Context, key string, values map[string]interface{})
+	HDel(ctx context.Context, key string, fields ...string)
+}
+
+type StringResult interface {
+	Result() (string, error)
+}
+
+type IntResult interface {
+	Result() (int64, error)
+}
+
+// Script is a Lua script, sent by hash and loaded on first use.
+type Script struct {
+	script *redis.Script
+}
+
+func NewScript(src string) *Script {
+	return &Script{script: redis.NewScript(src)}
+}
+
+func (s *Script) Hash() string {
+	return s.script.Hash()
+}
+
+// Message is a message received on a subscribed channel.
+type Message struct {
+	Channel string
+	Payload string
+}
+
+type Subscription interface {
+	// Channel returns the received messages. It is closed when the subscription is closed.
+	Channel() <-chan *Message
+	Close() error
 }
 
+var _ IRedisClient = (*RedisClient)(nil)
+
 type RedisClient struct {
-	client *redis.Client
+	keyNamer
+	client redis.UniversalClient
 }
 
-func NewRedisClient(cfg config.Redis) *RedisClient {
-	rdb := redis.NewClient(&redis.Options{
-		Addr:     cfg.Addr,
-		Password: cfg.Password, // no password set
-		DB:       cfg.DB,       // use default DB
-	})
-	return &RedisClient{
-		client: rdb,
+const (
+	RedisModeSingle    = "single"
+	RedisModeSentinel  = "sentinel"
+	RedisModeCluster   = "cluster"
+	RedisModeUniversal = "universal"
+)
+
+const defaultRedisPingTimeout = 5 * time.Second
+
+// NewRedisClient connects to Redis according to cfg.Mode and pings it,
+// so that a wrong address or credentials fail at startup instead of on the first request.
+// Every key built by GetKeyName is prefixed with namespace, and hooks run around every command.
+func NewRedisClient(cfg config.Redis, namespace string, hooks ...redis.Hook) (*RedisClient, error) {
+	opts, err := redisOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var rdb redis.UniversalClient
+	switch cfg.Mode {
+	case "", RedisModeSingle:
+		rdb = redis.NewClient(opts.Simple())
+	case RedisModeSentinel:
+		if opts.MasterName == "" {
+			return nil, errors.New("redis: sentinel mode requires MasterName")
+		}
+		rdb = redis.NewFailoverClient(opts.Failover())
+	case RedisModeCluster:
+		rdb = redis.NewClusterClient(opts.Cluster())
+	case RedisModeUniversal:
+		rdb = redis.NewUniversalClient(opts)
+	default:
+		return nil, fmt.Errorf("redis: unknown mode %q", cfg.Mode)
+	}
+
+	rdb.AddHook(redisTracingHook{})
+	for _, hook := range hooks {
+		rdb.AddHook(hook)
 	}
+
+	r := &RedisClient{
+		keyNamer: keyNamer{namespace: namespace},
+		client:   rdb,
+	}
+
+	timeout := cfg.DialTimeout
+	if timeout <= 0 {
+		timeout = defaultRedisPingTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := r.Ping(ctx); err != nil {
+		rdb.Close()
+		return nil, fmt.Errorf("redis: startup ping failed: %w", err)
+	}
+
+	return r, nil
 }
 
-func (r *RedisClient) GetKeyName(prefix string, key string) string {
-	return fmt.Sprintf("%s:%s", prefix, key)
+func redisOptions(cfg config.Redis) (*redis.UniversalOptions, error) {
+	addrs := cfg.Addrs
+	if len(addrs) == 0 && cfg.Addr != "" {
+		addrs = []string{cfg.Addr}
+	}
+
+	tlsConfig, err := redisTLSConfig(cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	return &redis.UniversalOptions{
+		Addrs:            addrs,
+		DB:               cfg.DB,
+		Username:         cfg.Username,
+		Password:         cfg.Password.Value(),
+		SentinelUsername: cfg.SentinelUsername,
+		SentinelPassword: cfg.SentinelPassword.Value(),
+		MasterName:       cfg.MasterName,
+		PoolSize:         cfg.PoolSize,
+		MinIdleConns:     cfg.MinIdleConns,
+		DialTimeout:      cfg.DialTimeout,
+		ReadTimeout:      cfg.ReadTimeout,
+		WriteTimeout:     cfg.WriteTimeout,
+		PoolTimeout:      cfg.PoolTimeout,
+		TLSConfig:        tlsConfig,
+	}, nil
+}
+
+func redisTLSConfig(cfg config.RedisTLS) (*tls.Config, error) {
+	if !cfg.Enable {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("redis: read CA file: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("redis: no certificate found in %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("redis: load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+func (r *RedisClient) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
+// Close closes every connection of the client.
+func (r *RedisClient) Close() error {
+	return r.client.Close()
 }
 
 func (r *RedisClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
 	return r.client.Set(ctx, key, value, expiration).Err()
 }
 
+func (r *RedisClient) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	return r.client.SetNX(ctx, key, value, expiration).Result()
+}
+
 func (r *RedisClient) Get(ctx context.Context, key string) (string, error) {
 	return r.client.Get(ctx, key).Result()
 }
 
+func (r *RedisClient) GetDel(ctx context.Context, key string) (string, error) {
+	return r.client.GetDel(ctx, key).Result()
+}
+
 func (r *RedisClient) Del(ctx context.Context, keys ...string) error {
 	return r.client.Del(ctx, keys...).Err()
 }
+
+func (r *RedisClient) Expire(ctx context.Context, key string, expiration time.Duration) (bool, error) {
+	return r.client.Expire(ctx, key, expiration).Result()
+}
+
+func (r *RedisClient) Incr(ctx context.Context, key string) (int64, error) {
+	return r.client.Incr(ctx, key).Result()
+}
+
+func (r *RedisClient) MGet(ctx context.Context, keys ...string) ([]interface{}, error) {
+	return r.client.MGet(ctx, keys...).Result()
+}
+
+func (r *RedisClient) MSet(ctx context.Context, values map[string]interface{}) error {
+	return r.client.MSet(ctx, values).Err()
+}
+
+func (r *RedisClient) HSet(ctx context.Context, key string, values map[string]interface{}) error {
+	return r.client.HSet(ctx, key, values).Err()
+}
+
+func (r *RedisClient) HGet(ctx context.Context, key string, field string) (string, error) {
+	return r.client.HGet(ctx, key, field).Result()
+}
+
+func (r *RedisClient) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	return r.client.HGetAll(ctx, key).Result()
+}
+
+func (r *RedisClient) HDel(ctx context.Context, key string, fields ...string) error {
+	return r.client.HDel(ctx, key, fields...).Err()
+}
+
+func (r *RedisClient) Pipeline(ctx context.Context, fn func(pipe Pipeliner) error) error {
+	cmds, err := r.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		return fn(&redisPipeliner{pipe: p})
+	})
+	return pipelineError(cmds, err)
+}
+
+func (r *RedisClient) TxPipeline(ctx context.Context, fn func(pipe Pipeliner) error) error {
+	cmds, err := r.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		return fn(&redisPipeliner{pipe: p})
+	})
+	return pipelineError(cmds, err)
+}
+
+// pipelineError ignores redis.Nil, a missing key is reported by the result of its own command.
+func pipelineError(cmds []redis.Cmder, err error) error {
+	if err == nil || !errors.Is(err, redis.Nil) {
+		return err
+	}
+
+	for _, cmd := range cmds {
+		if cmdErr := cmd.Err(); cmdErr != nil && !errors.Is(cmdErr, redis.Nil) {
+			return cmdErr
+		}
+	}
+	return nil
+}
+
+func (r *RedisClient) RunScript(ctx context.Context, script *Script, keys []string, args ...interface{}) (interface{}, error) {
+	return script.script.Run(ctx, r.client, keys, args...).Result()
+}
+
+func (r *RedisClient) Publish(ctx context.Context, channel string, message interface{}) error {
+	return r.client.Publish(ctx, channel, message).Err()
+}
+
+func (r *RedisClient) Subscribe(ctx context.Context, channels ...string) (Subscription, error) {
+	pubsub := r.client.Subscribe(ctx, channels...)
+
+	// Wait for the confirmation so that messages published right after Subscribe returns are not missed.
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+
+	sub := &redisSubscription{
+		pubsub: pubsub,
+		ch:     make(chan *Message),
+		done:   make(chan struct{}),
+	}
+	go sub.forward()
+
+	return sub, nil
+}
+
+type redisPipeliner struct {
+	pipe redis.Pipeliner
+}
+
+func (p *redisPipeliner) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) {
+	p.pipe.Set(ctx, key, value, expiration)
+}
+
+func (p *redisPipeliner) Get(ctx context.Context, key string) StringResult {
+	return p.pipe.Get(ctx, key)
+}
+
+func (p *redisPipeliner) Del(ctx context.Context, keys ...string) {
+	p.pipe.Del(ctx, keys...)
+}
+
+func (p *redisPipeliner) Expire(ctx context.Context, key string, expiration time.Duration) {
+	p.pipe.Expire(ctx, key, expiration)
+}
+
+func (p *redisPipeliner) Incr(ctx context.Context, key string) IntResult {
+	return p.pipe.Incr(ctx, key)
+}
+
+func (p *redisPipeliner) HSet(ctx context.Context, key string, values map[string]interface{}) {
+	p.pipe.HSet(ctx, key, values)
+}
+
+func (p *redisPipeliner) HDel(ctx context.Context, key string, fields ...string) {
+	p.pipe.HDel(ctx, key, fields...)
+}
+
+type redisSubscription struct {
+	pubsub    *redis.PubSub
+	ch        chan *Message
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (s *redisSubscription) forward() {
+	defer close(s.ch)
+
+	for msg := range s.pubsub.Channel() {
+		select {
+		case s.ch <- &Message{Channel: msg.Channel, Payload: msg.Payload}:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *redisSubscription) Channel() <-chan *Message {
+	return s.ch
+}
+
+func (s *redisSubscription) Close() error {
+	s.closeOnce.Do(func() { close(s.done) })
+	return s.pubsub.Close()
+}
@@ -0,0 +1,147 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/nutsp/golang-clean-architecture/config"
+)
+
+const scanCount = 500
+
+// KeyNamespace returns the prefix of every key of the application:
+// cfg.Namespace when set, "<app name>:<environment>" otherwise.
+func KeyNamespace(app config.AppConfig, cfg config.Redis) string {
+	if cfg.Namespace != "" {
+		return cfg.Namespace
+	}
+
+	var parts []string
+	for _, part := range []string{app.Name, app.Environment} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ":")
+}
+
+// VersionedPrefix appends a schema version to a key prefix.
+// Bumping the version when the shape of a cached value changes makes entries written
+// with the old shape unreachable; they expire with their TTL or can be purged.
+func VersionedPrefix(prefix string, version int) string {
+	return fmt.Sprintf("%s:v%d", prefix, version)
+}
+
+// keyNamer builds keys under a namespace.
+type keyNamer struct {
+	namespace string
+}
+
+func (n keyNamer) GetKeyName(prefix string, key string) string {
+	if n.namespace == "" {
+		return fmt.Sprintf("%s:%s", prefix, key)
+	}
+	return fmt.Sprintf("%s:%s:%s", n.namespace, prefix, key)
+}
+
+// Namespace returns the prefix added to every key built by GetKeyName.
+func (n keyNamer) Namespace() string {
+	return n.namespace
+}
+
+// NamespacePattern returns the SCAN pattern matching every key of the namespace.
+func (n keyNamer) NamespacePattern() string {
+	if n.namespace == "" {
+		return "*"
+	}
+	return n.namespace + ":*"
+}
+
+// Scan calls fn with batches of keys matching the glob pattern. On a cluster every master is
+// scanned concurrently, so fn must be safe for concurrent use.
+func (r *RedisClient) Scan(ctx context.Context, match string, fn func(keys []string) error) error {
+	if cluster, ok := r.client.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			return scan(ctx, node, match, fn)
+		})
+	}
+	return scan(ctx, r.client, match, fn)
+}
+
+func scan(ctx context.Context, c redis.Cmdable, match string, fn func(keys []string) error) error {
+	var cursor uint64
+	for {
+		keys, next, err := c.Scan(ctx, cursor, match, scanCount).Result()
+		if err != nil {
+			return err
+		}
+
+		if len(keys) > 0 {
+			if err := fn(keys); err != nil {
+				return err
+			}
+		}
+
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// PurgeKeys deletes every key matching the glob pattern and returns how many were found.
+// With dryRun the keys are only counted.
+func PurgeKeys(ctx context.Context, client IRedisClient, match string, dryRun bool) (int64, error) {
+	var mu sync.Mutex
+	var n int64
+
+	err := client.Scan(ctx, match, func(keys []string) error {
+		if !dryRun {
+			// One DEL per key, so keys of different cluster slots can be deleted in the same batch.
+			err := client.Pipeline(ctx, func(pipe Pipeliner) error {
+				for _, key := range keys {
+					pipe.Del(ctx, key)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		mu.Lock()
+		n += int64(len(keys))
+		mu.Unlock()
+		return nil
+	})
+
+	return n, err
+}
+
+// matchGlob reports whether key matches a Redis glob pattern made of literals, '*' and '?'.
+func matchGlob(pattern, key string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for i := len(key); i >= 0; i-- {
+				if matchGlob(pattern[1:], key[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(key) == 0 {
+				return false
+			}
+		default:
+			if len(key) == 0 || pattern[0] != key[0] {
+				return false
+			}
+		}
+		pattern, key = pattern[1:], key[1:]
+	}
+	return len(key) == 0
+}
@@ -0,0 +1,151 @@
+package datasource
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrLockNotObtained = errors.New("redis lock: not obtained")
+	ErrLockNotHeld     = errors.New("redis lock: not held")
+	ErrLockTTL         = errors.New("redis lock: ttl must be at least 1ms")
+)
+
+// minLockRefreshInterval bounds how often KeepAlive refreshes a lock.
+const minLockRefreshInterval = time.Millisecond
+
+var (
+	// releaseLockScript deletes the lock only if it still belongs to the caller.
+	releaseLockScript = NewScript(`if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) else return 0 end`)
+	// refreshLockScript extends the lock only if it still belongs to the caller.
+	refreshLockScript = NewScript(`if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("pexpire", KEYS[1], ARGV[2]) else return 0 end`)
+)
+
+// Lock is a distributed lock held on a single Redis key with SETNX.
+type Lock struct {
+	client IRedisClient
+	key    string
+	token  string
+	ttl    time.Duration
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// ObtainLock acquires the lock on key for ttl. With a positive retry it polls at that interval
+// until the lock is free or ctx is done, otherwise it gives up at once with ErrLockNotObtained.
+// Redis expires keys in milliseconds, a shorter ttl is rejected with ErrLockTTL.
+func ObtainLock(ctx context.Context, client IRedisClient, key string, ttl time.Duration, retry time.Duration) (*Lock, error) {
+	if ttl < time.Millisecond {
+		return nil, ErrLockTTL
+	}
+
+	token, err := lockToken()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		ok, err := client.SetNX(ctx, key, token, ttl)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return &Lock{client: client, key: key, token: token, ttl: ttl, stop: make(chan struct{})}, nil
+		}
+
+		if retry <= 0 {
+			return nil, ErrLockNotObtained
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ErrLockNotObtained
+		case <-time.After(retry):
+		}
+	}
+}
+
+func lockToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (l *Lock) Key() string {
+	return l.key
+}
+
+// Refresh resets the expiration of the lock to its ttl. It returns ErrLockNotHeld when the lock expired
+// or was taken by someone else.
+func (l *Lock) Refresh(ctx context.Context) error {
+	res, err := l.client.RunScript(ctx, refreshLockScript, []string{l.key}, l.token, l.ttl.Milliseconds())
+	if err != nil {
+		return err
+	}
+	if n, _ := res.(int64); n == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// KeepAlive refreshes the lock every interval in the background until the lock is released or
+// ctx is done. A non-positive interval defaults to a third of the ttl, and any interval is at
+// least a millisecond. The returned context is
+// cancelled when the lock is lost, with ErrLockNotHeld as its cause when it was taken by
+// someone else, or the last refresh error once the lock may have expired: work protected by the
+// lock should run under it. Refresh errors are retried until then.
+func (l *Lock) KeepAlive(ctx context.Context, interval time.Duration) context.Context {
+	if interval <= 0 {
+		interval = l.ttl / 3
+	}
+	interval = max(interval, minLockRefreshInterval)
+	lockCtx, cancel := context.WithCancelCause(ctx)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		expiresAt := time.Now().Add(l.ttl)
+
+		for {
+			select {
+			case <-lockCtx.Done():
+				return
+			case <-l.stop:
+				cancel(nil)
+				return
+			case <-ticker.C:
+				err := l.Refresh(lockCtx)
+				switch {
+				case err == nil:
+					expiresAt = time.Now().Add(l.ttl)
+				case errors.Is(err, ErrLockNotHeld), !time.Now().Add(interval).Before(expiresAt):
+					cancel(err)
+					return
+				}
+			}
+		}
+	}()
+
+	return lockCtx
+}
+
+// Release stops the renewal and deletes the lock. It returns ErrLockNotHeld when the lock had already expired.
+func (l *Lock) Release(ctx context.Context) error {
+	l.stopOnce.Do(func() { close(l.stop) })
+
+	res, err := l.client.RunScript(ctx, releaseLockScript, []string{l.key}, l.token)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.(int64); n == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
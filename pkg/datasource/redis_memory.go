@@ -0,0 +1,637 @@
+package datasource
+
+import (
+	"context"
+	"encoding"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+var (
+	errWrongType          = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+	errPipelineNotRun     = errors.New("redis: pipeline has not run")
+	errScriptNotSupported = errors.New("redis: script is not registered on the in-memory client")
+)
+
+const memorySubscriptionBuffer = 100
+
+var _ IRedisClient = (*MemoryRedisClient)(nil)
+
+// MemoryScriptFunc is the Go implementation of a Lua script for MemoryRedisClient.
+// It runs atomically and must only touch data through the given MemoryData.
+type MemoryScriptFunc func(data *MemoryData, keys []string, args []interface{}) (interface{}, error)
+
+type memoryEntry struct {
+	value    string
+	hash     map[string]string
+	expireAt time.Time
+}
+
+// MemoryRedisClient is an in-process IRedisClient for tests.
+// Lua scripts cannot run, their Go implementation has to be registered with RegisterScript;
+// the scripts used by Lock are registered already.
+type MemoryRedisClient struct {
+	keyNamer
+	mu      sync.Mutex
+	data    map[string]*memoryEntry
+	scripts map[string]MemoryScriptFunc
+	subs    map[string][]*memorySubscription
+	now     func() time.Time
+}
+
+func NewMemoryRedisClient() *MemoryRedisClient {
+	m := &MemoryRedisClient{
+		data:    make(map[string]*memoryEntry),
+		scripts: make(map[string]MemoryScriptFunc),
+		subs:    make(map[string][]*memorySubscription),
+		now:     time.Now,
+	}
+
+	m.RegisterScript(releaseLockScript, func(data *MemoryData, keys []string, args []interface{}) (interface{}, error) {
+		if val, err := data.Get(keys[0]); err != nil || val != args[0] {
+			return int64(0), nil
+		}
+		return data.Del(keys[0]), nil
+	})
+	m.RegisterScript(refreshLockScript, func(data *MemoryData, keys []string, args []interface{}) (interface{}, error) {
+		if val, err := data.Get(keys[0]); err != nil || val != args[0] {
+			return int64(0), nil
+		}
+		ms, _ := args[1].(int64)
+		if data.Expire(keys[0], time.Duration(ms)*time.Millisecond) {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	})
+
+	return m
+}
+
+// RegisterScript makes RunScript execute fn for script.
+func (m *MemoryRedisClient) RegisterScript(script *Script, fn MemoryScriptFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.scripts[script.Hash()] = fn
+}
+
+// SetClock replaces the clock used for expirations.
+func (m *MemoryRedisClient) SetClock(now func() time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.now = now
+}
+
+// Len returns the number of keys that have not expired.
+func (m *MemoryRedisClient) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	n := 0
+	for key := range m.data {
+		if m.entry(key) != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// SetNamespace sets the prefix added by GetKeyName.
+func (m *MemoryRedisClient) SetNamespace(namespace string) {
+	m.keyNamer = keyNamer{namespace: namespace}
+}
+
+// entry returns the live entry of key, removing it when it has expired. The caller must hold mu.
+func (m *MemoryRedisClient) entry(key string) *memoryEntry {
+	e, ok := m.data[key]
+	if !ok {
+		return nil
+	}
+	if !e.expireAt.IsZero() && !m.now().Before(e.expireAt) {
+		delete(m.data, key)
+		return nil
+	}
+	return e
+}
+
+func (m *MemoryRedisClient) expireAt(expiration time.Duration) time.Time {
+	if expiration <= 0 {
+		return time.Time{}
+	}
+	return m.now().Add(expiration)
+}
+
+func (m *MemoryRedisClient) set(key string, value interface{}, expiration time.Duration) error {
+	s, err := toString(value)
+	if err != nil {
+		return err
+	}
+	m.data[key] = &memoryEntry{value: s, expireAt: m.expireAt(expiration)}
+	return nil
+}
+
+func (m *MemoryRedisClient) get(key string) (string, error) {
+	e := m.entry(key)
+	if e == nil {
+		return "", redis.Nil
+	}
+	if e.hash != nil {
+		return "", errWrongType
+	}
+	return e.value, nil
+}
+
+func (m *MemoryRedisClient) del(keys ...string) int64 {
+	var n int64
+	for _, key := range keys {
+		if m.entry(key) != nil {
+			delete(m.data, key)
+			n++
+		}
+	}
+	return n
+}
+
+func (m *MemoryRedisClient) expire(key string, expiration time.Duration) bool {
+	e := m.entry(key)
+	if e == nil {
+		return false
+	}
+	if expiration <= 0 {
+		delete(m.data, key)
+		return true
+	}
+	e.expireAt = m.now().Add(expiration)
+	return true
+}
+
+func (m *MemoryRedisClient) incr(key string) (int64, error) {
+	e := m.entry(key)
+	if e == nil {
+		e = &memoryEntry{value: "0"}
+		m.data[key] = e
+	}
+	if e.hash != nil {
+		return 0, errWrongType
+	}
+
+	n, err := strconv.ParseInt(e.value, 10, 64)
+	if err != nil {
+		return 0, errors.New("ERR value is not an integer or out of range")
+	}
+	n++
+	e.value = strconv.FormatInt(n, 10)
+	return n, nil
+}
+
+func (m *MemoryRedisClient) hset(key string, values map[string]interface{}) error {
+	e := m.entry(key)
+	if e == nil {
+		e = &memoryEntry{hash: make(map[string]string)}
+		m.data[key] = e
+	}
+	if e.hash == nil {
+		return errWrongType
+	}
+
+	for field, value := range values {
+		s, err := toString(value)
+		if err != nil {
+			return err
+		}
+		e.hash[field] = s
+	}
+	return nil
+}
+
+func (m *MemoryRedisClient) hdel(key string, fields ...string) error {
+	e := m.entry(key)
+	if e == nil {
+		return nil
+	}
+	if e.hash == nil {
+		return errWrongType
+	}
+
+	for _, field := range fields {
+		delete(e.hash, field)
+	}
+	if len(e.hash) == 0 {
+		delete(m.data, key)
+	}
+	return nil
+}
+
+func (m *MemoryRedisClient) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// Close does nothing, the memory client holds no connection.
+func (m *MemoryRedisClient) Close() error {
+	return nil
+}
+
+func (m *MemoryRedisClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.set(key, value, expiration)
+}
+
+func (m *MemoryRedisClient) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.entry(key) != nil {
+		return false, nil
+	}
+	return true, m.set(key, value, expiration)
+}
+
+func (m *MemoryRedisClient) Get(ctx context.Context, key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.get(key)
+}
+
+func (m *MemoryRedisClient) GetDel(ctx context.Context, key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	val, err := m.get(key)
+	if err != nil {
+		return "", err
+	}
+	m.del(key)
+	return val, nil
+}
+
+func (m *MemoryRedisClient) Del(ctx context.Context, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.del(keys...)
+	return nil
+}
+
+func (m *MemoryRedisClient) Expire(ctx context.Context, key string, expiration time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.expire(key, expiration), nil
+}
+
+func (m *MemoryRedisClient) Incr(ctx context.Context, key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.incr(key)
+}
+
+func (m *MemoryRedisClient) MGet(ctx context.Context, keys ...string) ([]interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	values := make([]interface{}, len(keys))
+	for i, key := range keys {
+		// Like Redis, keys that are missing or hold another type are reported as nil.
+		if val, err := m.get(key); err == nil {
+			values[i] = val
+		}
+	}
+	return values, nil
+}
+
+func (m *MemoryRedisClient) MSet(ctx context.Context, values map[string]interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, value := range values {
+		if err := m.set(key, value, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MemoryRedisClient) HSet(ctx context.Context, key string, values map[string]interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.hset(key, values)
+}
+
+func (m *MemoryRedisClient) HGet(ctx context.Context, key string, field string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := m.entry(key)
+	if e == nil {
+		return "", redis.Nil
+	}
+	if e.hash == nil {
+		return "", errWrongType
+	}
+
+	val, ok := e.hash[field]
+	if !ok {
+		return "", redis.Nil
+	}
+	return val, nil
+}
+
+func (m *MemoryRedisClient) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := make(map[string]string)
+	e := m.entry(key)
+	if e == nil {
+		return result, nil
+	}
+	if e.hash == nil {
+		return nil, errWrongType
+	}
+
+	for field, val := range e.hash {
+		result[field] = val
+	}
+	return result, nil
+}
+
+func (m *MemoryRedisClient) HDel(ctx context.Context, key string, fields ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.hdel(key, fields...)
+}
+
+func (m *MemoryRedisClient) Scan(ctx context.Context, match string, fn func(keys []string) error) error {
+	m.mu.Lock()
+	var keys []string
+	for key := range m.data {
+		if m.entry(key) != nil && matchGlob(match, key) {
+			keys = append(keys, key)
+		}
+	}
+	m.mu.Unlock()
+
+	if len(keys) == 0 {
+		return nil
+	}
+	return fn(keys)
+}
+
+// Pipeline runs the queued commands atomically, so it behaves like TxPipeline.
+func (m *MemoryRedisClient) Pipeline(ctx context.Context, fn func(pipe Pipeliner) error) error {
+	return m.TxPipeline(ctx, fn)
+}
+
+func (m *MemoryRedisClient) TxPipeline(ctx context.Context, fn func(pipe Pipeliner) error) error {
+	pipe := &memoryPipeliner{}
+	if err := fn(pipe); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var firstErr error
+	for _, cmd := range pipe.cmds {
+		if err := cmd(m); err != nil && firstErr == nil && !errors.Is(err, redis.Nil) {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (m *MemoryRedisClient) RunScript(ctx context.Context, script *Script, keys []string, args ...interface{}) (interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fn, ok := m.scripts[script.Hash()]
+	if !ok {
+		return nil, errScriptNotSupported
+	}
+	return fn(&MemoryData{m: m}, keys, args)
+}
+
+// Publish delivers message to the current subscribers of channel.
+// A subscriber that does not keep up loses messages once its buffer is full.
+func (m *MemoryRedisClient) Publish(ctx context.Context, channel string, message interface{}) error {
+	payload, err := toString(message)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, sub := range m.subs[channel] {
+		select {
+		case sub.ch <- &Message{Channel: channel, Payload: payload}:
+		default:
+		}
+	}
+	return nil
+}
+
+func (m *MemoryRedisClient) Subscribe(ctx context.Context, channels ...string) (Subscription, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sub := &memorySubscription{
+		client:   m,
+		channels: channels,
+		ch:       make(chan *Message, memorySubscriptionBuffer),
+	}
+	for _, channel := range channels {
+		m.subs[channel] = append(m.subs[channel], sub)
+	}
+	return sub, nil
+}
+
+// MemoryData gives registered scripts access to the data of a MemoryRedisClient while it is locked.
+type MemoryData struct {
+	m *MemoryRedisClient
+}
+
+func (d *MemoryData) Get(key string) (string, error) {
+	return d.m.get(key)
+}
+
+func (d *MemoryData) Set(key string, value interface{}, expiration time.Duration) error {
+	return d.m.set(key, value, expiration)
+}
+
+func (d *MemoryData) Del(keys ...string) int64 {
+	return d.m.del(keys...)
+}
+
+func (d *MemoryData) Expire(key string, expiration time.Duration) bool {
+	return d.m.expire(key, expiration)
+}
+
+func (d *MemoryData) Incr(key string) (int64, error) {
+	return d.m.incr(key)
+}
+
+func (d *MemoryData) HSet(key string, values map[string]interface{}) error {
+	return d.m.hset(key, values)
+}
+
+func (d *MemoryData) HGetAll(key string) map[string]string {
+	result := make(map[string]string)
+	if e := d.m.entry(key); e != nil {
+		for field, val := range e.hash {
+			result[field] = val
+		}
+	}
+	return result
+}
+
+// TTL returns the remaining time to live of key, or 0 when it has no expiration or does not exist.
+func (d *MemoryData) TTL(key string) time.Duration {
+	e := d.m.entry(key)
+	if e == nil || e.expireAt.IsZero() {
+		return 0
+	}
+	return e.expireAt.Sub(d.m.now())
+}
+
+// Now returns the time of the clock of the client.
+func (d *MemoryData) Now() time.Time {
+	return d.m.now()
+}
+
+type memoryResult[T any] struct {
+	val T
+	err error
+}
+
+func (r *memoryResult[T]) Result() (T, error) {
+	return r.val, r.err
+}
+
+type memoryPipeliner struct {
+	cmds []func(m *MemoryRedisClient) error
+}
+
+func (p *memoryPipeliner) queue(cmd func(m *MemoryRedisClient) error) {
+	p.cmds = append(p.cmds, cmd)
+}
+
+func (p *memoryPipeliner) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) {
+	p.queue(func(m *MemoryRedisClient) error {
+		return m.set(key, value, expiration)
+	})
+}
+
+func (p *memoryPipeliner) Get(ctx context.Context, key string) StringResult {
+	res := &memoryResult[string]{err: errPipelineNotRun}
+	p.queue(func(m *MemoryRedisClient) error {
+		res.val, res.err = m.get(key)
+		return res.err
+	})
+	return res
+}
+
+func (p *memoryPipeliner) Del(ctx context.Context, keys ...string) {
+	p.queue(func(m *MemoryRedisClient) error {
+		m.del(keys...)
+		return nil
+	})
+}
+
+func (p *memoryPipeliner) Expire(ctx context.Context, key string, expiration time.Duration) {
+	p.queue(func(m *MemoryRedisClient) error {
+		m.expire(key, expiration)
+		return nil
+	})
+}
+
+func (p *memoryPipeliner) Incr(ctx context.Context, key string) IntResult {
+	res := &memoryResult[int64]{err: errPipelineNotRun}
+	p.queue(func(m *MemoryRedisClient) error {
+		res.val, res.err = m.incr(key)
+		return res.err
+	})
+	return res
+}
+
+func (p *memoryPipeliner) HSet(ctx context.Context, key string, values map[string]interface{}) {
+	p.queue(func(m *MemoryRedisClient) error {
+		return m.hset(key, values)
+	})
+}
+
+func (p *memoryPipeliner) HDel(ctx context.Context, key string, fields ...string) {
+	p.queue(func(m *MemoryRedisClient) error {
+		return m.hdel(key, fields...)
+	})
+}
+
+type memorySubscription struct {
+	client   *MemoryRedisClient
+	channels []string
+	ch       chan *Message
+	closed   bool
+}
+
+func (s *memorySubscription) Channel() <-chan *Message {
+	return s.ch
+}
+
+func (s *memorySubscription) Close() error {
+	s.client.mu.Lock()
+	defer s.client.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	for _, channel := range s.channels {
+		subs := s.client.subs[channel]
+		for i, sub := range subs {
+			if sub == s {
+				s.client.subs[channel] = append(subs[:i], subs[i+1:]...)
+				break
+			}
+		}
+	}
+	close(s.ch)
+	return nil
+}
+
+// toString converts a value the way go-redis writes command arguments.
+func toString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case bool:
+		if v {
+			return "1", nil
+		}
+		return "0", nil
+	case encoding.BinaryMarshaler:
+		b, err := v.MarshalBinary()
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
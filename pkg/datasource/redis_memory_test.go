@@ -0,0 +1,208 @@
+package datasource_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/nutsp/golang-clean-architecture/config"
+	"github.com/nutsp/golang-clean-architecture/pkg/datasource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRedisClientStrings(t *testing.T) {
+	ctx := context.Background()
+	client := datasource.NewMemoryRedisClient()
+
+	now := time.Now()
+	client.SetClock(func() time.Time { return now })
+
+	require.NoError(t, client.Set(ctx, "a", 1, time.Minute))
+	require.NoError(t, client.MSet(ctx, map[string]interface{}{"b": "two", "c": []byte("three")}))
+
+	values, err := client.MGet(ctx, "a", "b", "missing")
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"1", "two", nil}, values)
+
+	ok, err := client.SetNX(ctx, "a", "other", 0)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	n, err := client.Incr(ctx, "a")
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, n)
+
+	now = now.Add(time.Minute)
+	_, err = client.Get(ctx, "a")
+	assert.True(t, errors.Is(err, redis.Nil), "key must expire")
+
+	val, err := client.GetDel(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, "two", val)
+	assert.Equal(t, 1, client.Len())
+}
+
+func TestMemoryRedisClientHashes(t *testing.T) {
+	ctx := context.Background()
+	client := datasource.NewMemoryRedisClient()
+
+	require.NoError(t, client.HSet(ctx, "user:1", map[string]interface{}{"name": "John", "age": 30}))
+	require.NoError(t, client.HSet(ctx, "user:1", map[string]interface{}{"name": "Jane"}))
+
+	name, err := client.HGet(ctx, "user:1", "name")
+	require.NoError(t, err)
+	assert.Equal(t, "Jane", name)
+
+	require.NoError(t, client.HDel(ctx, "user:1", "age"))
+	all, err := client.HGetAll(ctx, "user:1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"name": "Jane"}, all)
+
+	_, err = client.Get(ctx, "user:1")
+	assert.Error(t, err)
+}
+
+func TestMemoryRedisClientPipeline(t *testing.T) {
+	ctx := context.Background()
+	client := datasource.NewMemoryRedisClient()
+
+	var get datasource.StringResult
+	var incr datasource.IntResult
+	err := client.TxPipeline(ctx, func(pipe datasource.Pipeliner) error {
+		pipe.Set(ctx, "a", "1", 0)
+		incr = pipe.Incr(ctx, "a")
+		get = pipe.Get(ctx, "missing")
+		return nil
+	})
+	require.NoError(t, err)
+
+	n, err := incr.Result()
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, n)
+
+	_, err = get.Result()
+	assert.True(t, errors.Is(err, redis.Nil))
+}
+
+func TestMemoryRedisClientPubSub(t *testing.T) {
+	ctx := context.Background()
+	client := datasource.NewMemoryRedisClient()
+
+	sub, err := client.Subscribe(ctx, "events")
+	require.NoError(t, err)
+
+	require.NoError(t, client.Publish(ctx, "events", "hello"))
+	require.NoError(t, client.Publish(ctx, "other", "ignored"))
+
+	msg := <-sub.Channel()
+	assert.Equal(t, &datasource.Message{Channel: "events", Payload: "hello"}, msg)
+
+	require.NoError(t, sub.Close())
+	_, open := <-sub.Channel()
+	assert.False(t, open)
+}
+
+func TestLock(t *testing.T) {
+	ctx := context.Background()
+	client := datasource.NewMemoryRedisClient()
+
+	lock, err := datasource.ObtainLock(ctx, client, "lock:a", time.Minute, 0)
+	require.NoError(t, err)
+
+	_, err = datasource.ObtainLock(ctx, client, "lock:a", time.Minute, 0)
+	assert.ErrorIs(t, err, datasource.ErrLockNotObtained)
+
+	waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	_, err = datasource.ObtainLock(waitCtx, client, "lock:a", time.Minute, 5*time.Millisecond)
+	assert.ErrorIs(t, err, datasource.ErrLockNotObtained)
+
+	require.NoError(t, lock.Refresh(ctx))
+	require.NoError(t, lock.Release(ctx))
+	assert.ErrorIs(t, lock.Release(ctx), datasource.ErrLockNotHeld)
+	assert.ErrorIs(t, lock.Refresh(ctx), datasource.ErrLockNotHeld)
+
+	_, err = datasource.ObtainLock(ctx, client, "lock:a", time.Minute, 0)
+	assert.NoError(t, err)
+}
+
+func TestObtainLockRejectsShortTTL(t *testing.T) {
+	ctx := context.Background()
+	client := datasource.NewMemoryRedisClient()
+
+	for _, ttl := range []time.Duration{-time.Second, 0, time.Microsecond} {
+		_, err := datasource.ObtainLock(ctx, client, "lock:a", ttl, 0)
+		assert.ErrorIs(t, err, datasource.ErrLockTTL, "ttl %s", ttl)
+	}
+
+	// The shortest ttl still gets a valid refresh interval.
+	lock, err := datasource.ObtainLock(ctx, client, "lock:a", time.Millisecond, 0)
+	require.NoError(t, err)
+	lockCtx := lock.KeepAlive(ctx, 0)
+	assert.Eventually(t, func() bool { return lockCtx.Err() != nil }, time.Second, time.Millisecond)
+}
+
+func TestLockKeepAlive(t *testing.T) {
+	ctx := context.Background()
+	client := datasource.NewMemoryRedisClient()
+
+	t.Run("renews the lock", func(t *testing.T) {
+		lock, err := datasource.ObtainLock(ctx, client, "lock:a", 30*time.Millisecond, 0)
+		require.NoError(t, err)
+		// A third of the ttl.
+		lockCtx := lock.KeepAlive(ctx, 0)
+
+		time.Sleep(80 * time.Millisecond)
+		assert.NoError(t, lockCtx.Err())
+		assert.NoError(t, lock.Release(ctx), "lock must have been renewed")
+		assert.Eventually(t, func() bool { return lockCtx.Err() != nil }, time.Second, time.Millisecond)
+	})
+
+	t.Run("reports a lost lock", func(t *testing.T) {
+		lock, err := datasource.ObtainLock(ctx, client, "lock:b", time.Minute, 0)
+		require.NoError(t, err)
+		lockCtx := lock.KeepAlive(ctx, 5*time.Millisecond)
+
+		require.NoError(t, client.Del(ctx, "lock:b"))
+
+		select {
+		case <-lockCtx.Done():
+			assert.ErrorIs(t, context.Cause(lockCtx), datasource.ErrLockNotHeld)
+		case <-time.After(time.Second):
+			t.Fatal("the lost lock was not reported")
+		}
+	})
+}
+
+func TestPurgeKeysByNamespace(t *testing.T) {
+	ctx := context.Background()
+	client := datasource.NewMemoryRedisClient()
+	client.SetNamespace("app:local")
+
+	userKey := client.GetKeyName(datasource.VersionedPrefix("users", 1), "1")
+	assert.Equal(t, "app:local:users:v1:1", userKey)
+
+	require.NoError(t, client.Set(ctx, userKey, "{}", 0))
+	require.NoError(t, client.Set(ctx, client.GetKeyName(datasource.VersionedPrefix("users", 2), "1"), "{}", 0))
+	require.NoError(t, client.Set(ctx, "app:staging:users:v1:1", "{}", 0))
+
+	n, err := datasource.PurgeKeys(ctx, client, client.NamespacePattern(), true)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, n)
+	assert.Equal(t, 3, client.Len(), "dry run must not delete")
+
+	n, err = datasource.PurgeKeys(ctx, client, "app:local:users:v1:*", false)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, n)
+	assert.Equal(t, 2, client.Len())
+}
+
+func TestKeyNamespace(t *testing.T) {
+	app := config.AppConfig{Name: "golang-clean-architecture", Environment: "staging"}
+
+	assert.Equal(t, "golang-clean-architecture:staging", datasource.KeyNamespace(app, config.Redis{}))
+	assert.Equal(t, "custom", datasource.KeyNamespace(app, config.Redis{Namespace: "custom"}))
+}
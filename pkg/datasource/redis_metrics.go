@@ -0,0 +1,53 @@
+package datasource
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/nutsp/golang-clean-architecture/pkg/metrics"
+)
+
+type redisStartKey struct{}
+
+// redisMetricsHook records the latency and failures of every command and pipeline.
+type redisMetricsHook struct {
+	metrics *metrics.RedisMetrics
+}
+
+// RedisMetricsHook returns a hook for NewRedisClient reporting to m.
+func RedisMetricsHook(m *metrics.RedisMetrics) redis.Hook {
+	return redisMetricsHook{metrics: m}
+}
+
+func (h redisMetricsHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return context.WithValue(ctx, redisStartKey{}, time.Now()), nil
+}
+
+func (h redisMetricsHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	if start, ok := ctx.Value(redisStartKey{}).(time.Time); ok {
+		h.metrics.Observe(cmd.Name(), redisCommandError(cmd.Err()) != nil, time.Since(start))
+	}
+	return nil
+}
+
+func (h redisMetricsHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	return context.WithValue(ctx, redisStartKey{}, time.Now()), nil
+}
+
+func (h redisMetricsHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	start, ok := ctx.Value(redisStartKey{}).(time.Time)
+	if !ok {
+		return nil
+	}
+
+	failed := false
+	for _, cmd := range cmds {
+		if redisCommandError(cmd.Err()) != nil {
+			failed = true
+			break
+		}
+	}
+	h.metrics.Observe("pipeline", failed, time.Since(start))
+	return nil
+}
@@ -0,0 +1,44 @@
+package datasource_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nutsp/golang-clean-architecture/config"
+	"github.com/nutsp/golang-clean-architecture/pkg/datasource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRedisClientFailsFast(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Redis
+	}{
+		{
+			name: "unreachable_node",
+			cfg:  config.Redis{Addr: "127.0.0.1:1", DialTimeout: 200 * time.Millisecond},
+		},
+		{
+			name: "unknown_mode",
+			cfg:  config.Redis{Mode: "replicated", Addr: "127.0.0.1:6379"},
+		},
+		{
+			name: "sentinel_without_master_name",
+			cfg:  config.Redis{Mode: datasource.RedisModeSentinel, Addrs: []string{"127.0.0.1:26379"}},
+		},
+		{
+			name: "missing_ca_file",
+			cfg:  config.Redis{Addr: "127.0.0.1:6379", TLS: config.RedisTLS{Enable: true, CAFile: "testdata/missing.pem"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			client, err := datasource.NewRedisClient(tt.cfg, "")
+			assert.Error(t, err)
+			assert.Nil(t, client)
+			assert.Less(t, time.Since(start), 5*time.Second)
+		})
+	}
+}
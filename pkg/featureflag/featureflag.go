@@ -0,0 +1,136 @@
+// Package featureflag turns features on for everyone, for a list of users or for a
+// percentage of them, so new endpoints and behaviors can be launched dark.
+package featureflag
+
+import (
+	"context"
+	"hash/fnv"
+	"strings"
+	"sync"
+
+	"github.com/nutsp/golang-clean-architecture/pkg/observability"
+)
+
+// Flag describes who a feature is on for. A zero Flag is off for everyone.
+type Flag struct {
+	Enable     bool     `json:"enable"`     // On for everyone.
+	Users      []string `json:"users"`      // On for these users.
+	Percentage float64  `json:"percentage"` // On for this share of the users, from 0 to 100.
+}
+
+// EnabledFor reports whether the feature name is on for user. Users are assigned to the
+// percentage by a hash of the flag name and the user, so a user keeps the feature while
+// the percentage grows, and each flag rolls out to different users. An empty user is only
+// covered by Enable.
+func (f Flag) EnabledFor(name, user string) bool {
+	if f.Enable {
+		return true
+	}
+	if user == "" {
+		return false
+	}
+	for _, u := range f.Users {
+		if u == user {
+			return true
+		}
+	}
+	return f.Percentage > 0 && bucket(name, user) < f.Percentage
+}
+
+// bucket places user in [0, 100) for the flag name, with a 0.01 resolution.
+func bucket(name, user string) float64 {
+	h := fnv.New32a()
+	h.Write([]byte(name + ":" + user))
+	return float64(h.Sum32()%10000) / 100
+}
+
+// Provider returns the flags from where they are stored.
+type Provider interface {
+	// Flag returns the flag name, and whether the provider knows it.
+	Flag(ctx context.Context, name string) (Flag, bool, error)
+}
+
+// Flags tells whether a feature is on, it is what handlers and use cases depend on.
+type Flags interface {
+	// Enabled reports whether the feature name is on for the user of ctx, see WithUser.
+	Enabled(ctx context.Context, name string) bool
+}
+
+// Service looks a flag up in its providers in order, the first one knowing the flag wins.
+// A flag no provider knows is off.
+type Service struct {
+	logger    observability.Logger
+	providers []Provider
+}
+
+func NewService(logger observability.Logger, providers ...Provider) *Service {
+	return &Service{
+		logger:    logger,
+		providers: providers,
+	}
+}
+
+// Enabled implements Flags. Flag names are not case sensitive. A failing provider is
+// skipped, so an outage of the flag storage falls back to the next provider rather
+// than failing the request.
+func (s *Service) Enabled(ctx context.Context, name string) bool {
+	name = strings.ToLower(name)
+
+	for _, provider := range s.providers {
+		flag, ok, err := provider.Flag(ctx, name)
+		if err != nil {
+			s.logger.WithContext(ctx).Error("FeatureFlag.Enabled", "flag", name, "error", err)
+			continue
+		}
+		if ok {
+			return flag.EnabledFor(name, UserFromContext(ctx))
+		}
+	}
+
+	return false
+}
+
+// MemoryProvider holds the flags in memory, such as the flags of the configuration.
+type MemoryProvider struct {
+	mu    sync.RWMutex
+	flags map[string]Flag
+}
+
+func NewMemoryProvider(flags map[string]Flag) *MemoryProvider {
+	p := &MemoryProvider{}
+	p.Set(flags)
+	return p
+}
+
+// Set replaces every flag, e.g. when the configuration is reloaded.
+func (p *MemoryProvider) Set(flags map[string]Flag) {
+	normalized := make(map[string]Flag, len(flags))
+	for name, flag := range flags {
+		normalized[strings.ToLower(name)] = flag
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.flags = normalized
+}
+
+func (p *MemoryProvider) Flag(_ context.Context, name string) (Flag, bool, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	flag, ok := p.flags[name]
+	return flag, ok, nil
+}
+
+type userKey struct{}
+
+// WithUser returns a copy of ctx carrying the user the flags are evaluated for.
+func WithUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// UserFromContext returns the user set by WithUser, or an empty string.
+func UserFromContext(ctx context.Context) string {
+	user, _ := ctx.Value(userKey{}).(string)
+	return user
+}
@@ -0,0 +1,203 @@
+package featureflag_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nutsp/golang-clean-architecture/config"
+	"github.com/nutsp/golang-clean-architecture/pkg/datasource"
+	"github.com/nutsp/golang-clean-architecture/pkg/featureflag"
+	"github.com/nutsp/golang-clean-architecture/pkg/observability"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlagEnabledFor(t *testing.T) {
+	assert.False(t, featureflag.Flag{}.EnabledFor("beta", "42"))
+	assert.True(t, featureflag.Flag{Enable: true}.EnabledFor("beta", ""))
+	assert.True(t, featureflag.Flag{Users: []string{"42"}}.EnabledFor("beta", "42"))
+	assert.False(t, featureflag.Flag{Users: []string{"42"}}.EnabledFor("beta", "7"))
+	assert.False(t, featureflag.Flag{Percentage: 100}.EnabledFor("beta", ""), "anonymous users are not rolled out to")
+	assert.True(t, featureflag.Flag{Percentage: 100}.EnabledFor("beta", "7"))
+}
+
+func TestFlagPercentage(t *testing.T) {
+	enabled := func(percentage float64) map[string]bool {
+		users := map[string]bool{}
+		for i := 0; i < 10000; i++ {
+			user := fmt.Sprint(i)
+			if (featureflag.Flag{Percentage: percentage}).EnabledFor("beta", user) {
+				users[user] = true
+			}
+		}
+		return users
+	}
+
+	ten, twenty := enabled(10), enabled(20)
+	assert.InDelta(t, 1000, len(ten), 150)
+	assert.InDelta(t, 2000, len(twenty), 200)
+	// Growing the rollout keeps the users it already covered.
+	for user := range ten {
+		assert.True(t, twenty[user], user)
+	}
+}
+
+// failingProvider fails every lookup.
+type failingProvider struct{}
+
+func (failingProvider) Flag(context.Context, string) (featureflag.Flag, bool, error) {
+	return featureflag.Flag{}, false, errors.New("connection refused")
+}
+
+func TestServiceEnabled(t *testing.T) {
+	overrides := featureflag.NewMemoryProvider(map[string]featureflag.Flag{"Beta": {}})
+	defaults := featureflag.NewMemoryProvider(map[string]featureflag.Flag{
+		"beta":   {Enable: true},
+		"search": {Users: []string{"42"}},
+	})
+	service := featureflag.NewService(observability.NewZapLogger(config.Logger{Level: "error"}), failingProvider{}, overrides, defaults)
+
+	ctx := featureflag.WithUser(context.Background(), "42")
+	// The first provider knowing the flag wins, a failing one is skipped.
+	assert.False(t, service.Enabled(ctx, "beta"))
+	assert.True(t, service.Enabled(ctx, "SEARCH"))
+	assert.False(t, service.Enabled(context.Background(), "search"))
+	assert.False(t, service.Enabled(ctx, "unknown"))
+
+	overrides.Set(nil)
+	assert.True(t, service.Enabled(ctx, "beta"))
+}
+
+// brokenRedis fails every HGETALL once broken, and waits for release when it is set.
+type brokenRedis struct {
+	datasource.IRedisClient
+	broken  bool
+	release chan struct{}
+	reads   int32
+}
+
+func (r *brokenRedis) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	atomic.AddInt32(&r.reads, 1)
+	if r.release != nil {
+		<-r.release
+	}
+	if r.broken {
+		return nil, errors.New("connection refused")
+	}
+	return r.IRedisClient.HGetAll(ctx, key)
+}
+
+func TestRedisProvider(t *testing.T) {
+	ctx := context.Background()
+	logger := observability.NewZapLogger(config.Logger{})
+	client := &brokenRedis{IRedisClient: datasource.NewMemoryRedisClient()}
+	now := time.Now()
+	provider := featureflag.NewRedisProvider(logger, client, time.Minute)
+	provider.SetClock(func() time.Time { return now })
+
+	_, ok, err := provider.Flag(ctx, "beta")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, provider.Set(ctx, "Beta", featureflag.Flag{Percentage: 5}))
+	flag, ok, err := provider.Flag(ctx, "beta")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 5.0, flag.Percentage)
+
+	// Another instance changes the flag, it is read once the cache expires.
+	require.NoError(t, client.HSet(ctx, client.GetKeyName("features", "flags"), map[string]interface{}{"beta": `{"enable":true}`}))
+	flag, _, _ = provider.Flag(ctx, "beta")
+	assert.False(t, flag.Enable)
+	now = now.Add(time.Minute)
+	flag, _, _ = provider.Flag(ctx, "beta")
+	assert.True(t, flag.Enable)
+
+	// The last flags read are served while Redis is down.
+	client.broken = true
+	now = now.Add(time.Minute)
+	flag, ok, err = provider.Flag(ctx, "beta")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, flag.Enable)
+
+	// A failed first read counts as no flags until the TTL expires.
+	reads := atomic.LoadInt32(&client.reads)
+	other := featureflag.NewRedisProvider(logger, client, 0)
+	_, _, err = other.Flag(ctx, "beta")
+	assert.ErrorContains(t, err, "connection refused")
+	_, ok, err = other.Flag(ctx, "beta")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, reads+1, atomic.LoadInt32(&client.reads))
+
+	client.broken = false
+	require.NoError(t, provider.Delete(ctx, "beta"))
+	_, ok, _ = provider.Flag(ctx, "beta")
+	assert.False(t, ok)
+}
+
+func TestRedisProviderSkipsMalformedFlags(t *testing.T) {
+	ctx := context.Background()
+	client := datasource.NewMemoryRedisClient()
+	provider := featureflag.NewRedisProvider(observability.NewZapLogger(config.Logger{}), client, time.Minute)
+
+	require.NoError(t, client.HSet(ctx, client.GetKeyName("features", "flags"), map[string]interface{}{
+		"beta":  `{"enable":true}`,
+		"alpha": `{"enable":`,
+	}))
+
+	flag, ok, err := provider.Flag(ctx, "beta")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, flag.Enable)
+
+	_, ok, err = provider.Flag(ctx, "alpha")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestRedisProviderReadsOnce(t *testing.T) {
+	ctx := context.Background()
+	client := &brokenRedis{IRedisClient: datasource.NewMemoryRedisClient(), release: make(chan struct{})}
+	now := time.Now()
+	provider := featureflag.NewRedisProvider(observability.NewZapLogger(config.Logger{}), client, time.Minute)
+	provider.SetClock(func() time.Time { return now })
+	require.NoError(t, client.HSet(ctx, client.GetKeyName("features", "flags"), map[string]interface{}{"beta": `{"enable":true}`}))
+
+	// Concurrent first calls wait for a single read.
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			flag, _, err := provider.Flag(ctx, "beta")
+			assert.NoError(t, err)
+			assert.True(t, flag.Enable)
+		}()
+	}
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&client.reads) == 1 }, time.Second, time.Millisecond)
+	client.release <- struct{}{}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&client.reads))
+
+	// Once expired, the other callers keep the flags already read during the next read.
+	now = now.Add(time.Minute)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _, _ = provider.Flag(ctx, "beta")
+	}()
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&client.reads) == 2 }, time.Second, time.Millisecond)
+	flag, ok, err := provider.Flag(ctx, "beta")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, flag.Enable)
+	client.release <- struct{}{}
+	<-done
+}
@@ -0,0 +1,170 @@
+package featureflag
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nutsp/golang-clean-architecture/pkg/datasource"
+	"github.com/nutsp/golang-clean-architecture/pkg/observability"
+)
+
+const defaultRedisCacheTTL = 10 * time.Second
+
+// RedisProvider reads the flags from a Redis hash, a field per flag holding the JSON of its
+// Flag, so they can be changed without a deployment:
+//
+//	HSET <namespace>:features:flags skip-email-check '{"users":["42"],"percentage":5}'
+//
+// The hash is read at most once per cache TTL, by a single caller while the others keep
+// using the flags already read. The last flags read are kept while Redis is unavailable, and
+// a failed first read counts as no flags until the TTL expires. Malformed fields are logged
+// and skipped.
+type RedisProvider struct {
+	logger   observability.Logger
+	client   datasource.IRedisClient
+	key      string
+	cacheTTL time.Duration
+	now      func() time.Time
+
+	mu       sync.Mutex
+	flags    map[string]Flag
+	loadedAt time.Time
+	loading  chan struct{} // Closed when the read in flight is over, nil when there is none.
+	version  int           // Incremented by the writes, so a read started before them is not kept.
+}
+
+// NewRedisProvider returns a provider caching the flags for cacheTTL, 10s when zero.
+func NewRedisProvider(logger observability.Logger, client datasource.IRedisClient, cacheTTL time.Duration) *RedisProvider {
+	if cacheTTL <= 0 {
+		cacheTTL = defaultRedisCacheTTL
+	}
+
+	return &RedisProvider{
+		logger:   logger,
+		client:   client,
+		key:      client.GetKeyName("features", "flags"),
+		cacheTTL: cacheTTL,
+		now:      time.Now,
+	}
+}
+
+// SetClock replaces the clock, for tests.
+func (p *RedisProvider) SetClock(now func() time.Time) {
+	p.now = now
+}
+
+func (p *RedisProvider) Flag(ctx context.Context, name string) (Flag, bool, error) {
+	p.mu.Lock()
+	fresh := p.flags != nil && p.now().Sub(p.loadedAt) < p.cacheTTL
+	if fresh || (p.flags != nil && p.loading != nil) {
+		flag, ok := p.flags[name]
+		p.mu.Unlock()
+		return flag, ok, nil
+	}
+
+	if loading := p.loading; loading != nil {
+		// Nothing was read yet, wait for the first read.
+		p.mu.Unlock()
+		select {
+		case <-loading:
+		case <-ctx.Done():
+			return Flag{}, false, ctx.Err()
+		}
+		return p.cached(name)
+	}
+
+	loading := make(chan struct{})
+	p.loading = loading
+	version := p.version
+	p.mu.Unlock()
+
+	// Other callers depend on this read, it must not end with the request of this one.
+	flags, err := p.load(context.WithoutCancel(ctx))
+
+	p.mu.Lock()
+	p.loading = nil
+	close(loading)
+	stale := p.flags != nil
+	if p.version == version {
+		if err == nil {
+			p.flags = flags
+		} else if !stale {
+			p.flags = map[string]Flag{}
+		}
+		p.loadedAt = p.now()
+	}
+	p.mu.Unlock()
+
+	if err != nil {
+		if !stale {
+			return Flag{}, false, err
+		}
+		// Serve the last flags read, the next read is attempted once the TTL expires again.
+		p.logger.WithContext(ctx).Error("RedisProvider.Flag", "error", err)
+	}
+	return p.cached(name)
+}
+
+func (p *RedisProvider) cached(name string) (Flag, bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	flag, ok := p.flags[name]
+	return flag, ok, nil
+}
+
+func (p *RedisProvider) load(ctx context.Context) (map[string]Flag, error) {
+	values, err := p.client.HGetAll(ctx, p.key)
+	if err != nil {
+		return nil, fmt.Errorf("featureflag: read %s: %w", p.key, err)
+	}
+
+	flags := make(map[string]Flag, len(values))
+	for name, value := range values {
+		var flag Flag
+		if err := json.Unmarshal([]byte(value), &flag); err != nil {
+			p.logger.WithContext(ctx).Error("RedisProvider.load", "flag", name, "error", err)
+			continue
+		}
+		flags[strings.ToLower(name)] = flag
+	}
+
+	return flags, nil
+}
+
+// Set stores flag in Redis, for every instance once their cache expires.
+func (p *RedisProvider) Set(ctx context.Context, name string, flag Flag) error {
+	value, err := json.Marshal(flag)
+	if err != nil {
+		return err
+	}
+	if err := p.client.HSet(ctx, p.key, map[string]interface{}{strings.ToLower(name): value}); err != nil {
+		return fmt.Errorf("featureflag: write %s: %w", p.key, err)
+	}
+
+	p.invalidate()
+	return nil
+}
+
+// Delete removes the flag name from Redis, the next provider decides again.
+func (p *RedisProvider) Delete(ctx context.Context, name string) error {
+	if err := p.client.HDel(ctx, p.key, strings.ToLower(name)); err != nil {
+		return fmt.Errorf("featureflag: delete %s: %w", p.key, err)
+	}
+
+	p.invalidate()
+	return nil
+}
+
+// invalidate makes the next call read the hash again.
+func (p *RedisProvider) invalidate() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.version++
+	p.loadedAt = time.Time{}
+}
@@ -0,0 +1,212 @@
+// Package health aggregates the checks of the dependencies of the application for the
+// liveness and readiness probes.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusUp       = "up"
+	StatusDown     = "down"
+	StatusDegraded = "degraded" // An optional dependency is down, the application can still serve.
+)
+
+const (
+	defaultTimeout  = 2 * time.Second
+	defaultCacheTTL = time.Second
+)
+
+// ErrShuttingDown is reported by readiness once the application started to shut down.
+var ErrShuttingDown = errors.New("health: shutting down")
+
+// Checker checks a single dependency.
+type Checker struct {
+	Name string
+	// Check returns an error when the dependency cannot be used. It should honour ctx.
+	Check func(ctx context.Context) error
+	// Optional dependencies degrade the report instead of failing readiness.
+	Optional bool
+}
+
+// CheckResult is the outcome of a single Checker.
+type CheckResult struct {
+	Status   string `json:"status"`
+	Optional bool   `json:"optional,omitempty"`
+	Duration string `json:"duration"`
+	// Error is not served with the report, it may disclose the addresses and credentials of a
+	// dependency. It is for the logs.
+	Error string `json:"-"`
+}
+
+// Report is the aggregated outcome of every Checker.
+type Report struct {
+	Status    string                 `json:"status"`
+	CheckedAt time.Time              `json:"checked_at"`
+	Checks    map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// Up reports whether the application can serve traffic.
+func (r Report) Up() bool {
+	return r.Status != StatusDown
+}
+
+// Health runs the registered checkers with a timeout each and caches the report,
+// so frequent probes do not hammer the dependencies.
+type Health struct {
+	timeout  time.Duration
+	cacheTTL time.Duration
+	now      func() time.Time
+
+	mu       sync.Mutex
+	checkers []Checker
+	cached   *Report
+
+	shuttingDown atomic.Bool
+}
+
+// New creates a Health running each check for at most timeout and caching reports for cacheTTL.
+// Zero values select 2s and 1s.
+func New(timeout, cacheTTL time.Duration, checkers ...Checker) *Health {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	if cacheTTL <= 0 {
+		cacheTTL = defaultCacheTTL
+	}
+
+	return &Health{
+		timeout:  timeout,
+		cacheTTL: cacheTTL,
+		now:      time.Now,
+		checkers: checkers,
+	}
+}
+
+// Register adds checkers to the readiness report.
+func (h *Health) Register(checkers ...Checker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.checkers = append(h.checkers, checkers...)
+	h.cached = nil
+}
+
+// SetShuttingDown makes readiness fail from now on, so load balancers stop sending
+// traffic while the server drains.
+func (h *Health) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+// Liveness reports whether the process is alive. It never checks dependencies,
+// otherwise an outage of a dependency would restart every instance.
+func (h *Health) Liveness() Report {
+	return Report{Status: StatusUp, CheckedAt: h.now()}
+}
+
+// Readiness runs every checker, or returns the cached report when it is recent enough.
+func (h *Health) Readiness(ctx context.Context) Report {
+	if h.shuttingDown.Load() {
+		return Report{
+			Status:    StatusDown,
+			CheckedAt: h.now(),
+			Checks: map[string]CheckResult{
+				"shutdown": {Status: StatusDown, Duration: "0s", Error: ErrShuttingDown.Error()},
+			},
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.cached != nil && h.now().Sub(h.cached.CheckedAt) < h.cacheTTL {
+		return *h.cached
+	}
+
+	// The report is shared with the next probes, so it must not fail because this caller went away.
+	report := h.run(context.WithoutCancel(ctx))
+	h.cached = &report
+
+	return report
+}
+
+func (h *Health) run(ctx context.Context) Report {
+	results := make([]CheckResult, len(h.checkers))
+
+	var wg sync.WaitGroup
+	for i, checker := range h.checkers {
+		wg.Add(1)
+		go func(i int, checker Checker) {
+			defer wg.Done()
+			results[i] = h.check(ctx, checker)
+		}(i, checker)
+	}
+	wg.Wait()
+
+	report := Report{
+		Status:    StatusUp,
+		CheckedAt: h.now(),
+		Checks:    make(map[string]CheckResult, len(h.checkers)),
+	}
+
+	for i, checker := range h.checkers {
+		result := results[i]
+		report.Checks[checker.Name] = result
+
+		if result.Status == StatusUp {
+			continue
+		}
+		if checker.Optional {
+			if report.Status == StatusUp {
+				report.Status = StatusDegraded
+			}
+			continue
+		}
+		report.Status = StatusDown
+	}
+
+	return report
+}
+
+func (h *Health) check(ctx context.Context, checker Checker) (result CheckResult) {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	start := h.now()
+	defer func() {
+		result.Duration = h.now().Sub(start).String()
+		result.Optional = checker.Optional
+	}()
+
+	// The check runs aside so one that ignores ctx still cannot block the probe past the timeout.
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- checker.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("timed out after %s: %w", h.timeout, err)
+		}
+		return CheckResult{Status: StatusDown, Error: err.Error()}
+	}
+
+	return CheckResult{Status: StatusUp}
+}
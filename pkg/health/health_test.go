@@ -0,0 +1,109 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nutsp/golang-clean-architecture/pkg/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func ok(context.Context) error { return nil }
+
+func TestReadinessAggregation(t *testing.T) {
+	tests := []struct {
+		name     string
+		checkers []health.Checker
+		status   string
+	}{
+		{
+			name:     "every dependency up",
+			checkers: []health.Checker{{Name: "redis", Check: ok}, {Name: "database", Check: ok}},
+			status:   health.StatusUp,
+		},
+		{
+			name: "required dependency down",
+			checkers: []health.Checker{
+				{Name: "redis", Check: func(context.Context) error { return errors.New("connection refused") }},
+				{Name: "mailer", Check: ok, Optional: true},
+			},
+			status: health.StatusDown,
+		},
+		{
+			name: "optional dependency down",
+			checkers: []health.Checker{
+				{Name: "redis", Check: ok},
+				{Name: "mailer", Check: func(context.Context) error { return errors.New("502") }, Optional: true},
+			},
+			status: health.StatusDegraded,
+		},
+		{
+			name: "panicking check",
+			checkers: []health.Checker{
+				{Name: "redis", Check: func(context.Context) error { panic("nil client") }},
+			},
+			status: health.StatusDown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := health.New(time.Second, time.Minute, tt.checkers...).Readiness(context.Background())
+
+			assert.Equal(t, tt.status, report.Status)
+			assert.Equal(t, tt.status != health.StatusDown, report.Up())
+			assert.Len(t, report.Checks, len(tt.checkers))
+		})
+	}
+}
+
+func TestReadinessTimeout(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+
+	h := health.New(20*time.Millisecond, time.Minute, health.Checker{
+		Name: "database",
+		// Ignores ctx on purpose.
+		Check: func(context.Context) error { <-block; return nil },
+	})
+
+	start := time.Now()
+	report := h.Readiness(context.Background())
+
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, health.StatusDown, report.Status)
+	assert.Contains(t, report.Checks["database"].Error, "timed out")
+}
+
+func TestReadinessCache(t *testing.T) {
+	var calls int32
+	h := health.New(time.Second, time.Minute, health.Checker{
+		Name: "redis",
+		Check: func(context.Context) error {
+			atomic.AddInt32(&calls, 1)
+			return nil
+		},
+	})
+
+	h.Readiness(context.Background())
+	h.Readiness(context.Background())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	h.Register(health.Checker{Name: "database", Check: ok})
+	report := h.Readiness(context.Background())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Len(t, report.Checks, 2)
+}
+
+func TestReadinessShuttingDown(t *testing.T) {
+	h := health.New(time.Second, time.Minute, health.Checker{Name: "redis", Check: ok})
+	assert.True(t, h.Readiness(context.Background()).Up())
+
+	h.SetShuttingDown()
+
+	assert.False(t, h.Readiness(context.Background()).Up())
+	assert.True(t, h.Liveness().Up())
+}
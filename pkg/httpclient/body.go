@@ -0,0 +1,111 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"strings"
+)
+
+var (
+	ApplicationFormUrlencoded string = "application/x-www-form-urlencoded"
+	ApplicationOctetStream    string = "application/octet-stream"
+)
+
+// BodyEncoder is implemented by request bodies that are not sent as JSON.
+// Request bodies of any other type are marshaled to JSON.
+type BodyEncoder interface {
+	Encode() (contentType string, data []byte, err error)
+}
+
+// FormBody sends the values form-encoded.
+type FormBody url.Values
+
+func (b FormBody) Encode() (string, []byte, error) {
+	return ApplicationFormUrlencoded, []byte(url.Values(b).Encode()), nil
+}
+
+// RawBody sends Data as is, with ContentType or application/octet-stream when empty.
+type RawBody struct {
+	ContentType string
+	Data        []byte
+}
+
+func (b RawBody) Encode() (string, []byte, error) {
+	contentType := b.ContentType
+	if contentType == "" {
+		contentType = ApplicationOctetStream
+	}
+	return contentType, b.Data, nil
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// MultipartFile is a file part of a MultipartBody.
+type MultipartFile struct {
+	FieldName   string
+	FileName    string
+	ContentType string // application/octet-stream when empty.
+	Content     []byte
+}
+
+// MultipartBody sends fields and files as multipart/form-data.
+type MultipartBody struct {
+	Fields map[string]string
+	Files  []MultipartFile
+}
+
+func (b MultipartBody) Encode() (string, []byte, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	for name, value := range b.Fields {
+		if err := w.WriteField(name, value); err != nil {
+			return "", nil, err
+		}
+	}
+
+	for _, file := range b.Files {
+		contentType := file.ContentType
+		if contentType == "" {
+			contentType = ApplicationOctetStream
+		}
+
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			quoteEscaper.Replace(file.FieldName), quoteEscaper.Replace(file.FileName)))
+		h.Set(ContentType, contentType)
+
+		part, err := w.CreatePart(h)
+		if err != nil {
+			return "", nil, err
+		}
+		if _, err := part.Write(file.Content); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return "", nil, err
+	}
+
+	return w.FormDataContentType(), buf.Bytes(), nil
+}
+
+func encodeBody(body interface{}) (string, []byte, error) {
+	switch b := body.(type) {
+	case BodyEncoder:
+		return b.Encode()
+	case []byte:
+		return RawBody{Data: b}.Encode()
+	default:
+		data, err := json.Marshal(body)
+		if err != nil {
+			return "", nil, err
+		}
+		return ApplicationJson, data, nil
+	}
+}
@@ -0,0 +1,107 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/nutsp/golang-clean-architecture/config"
+)
+
+// CircuitBreaker guards calls to a host and stops sending requests while the host keeps failing.
+type CircuitBreaker interface {
+	// Allow returns a *CircuitOpenError when a request to host must not be sent.
+	Allow(host string) error
+	// Record reports the outcome of a request that Allow let through, sent with ctx.
+	Record(ctx context.Context, host string, res *http.Response, err error)
+}
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+type hostCircuit struct {
+	state    circuitState
+	failures int
+	openedAt time.Time
+}
+
+// HostCircuitBreakers keeps an independent circuit for every host.
+// Transport errors and 5xx responses count as failures. A request abandoned by its caller, whose
+// context is done, says nothing about the host and is not counted.
+type HostCircuitBreakers struct {
+	FailureThreshold int
+	OpenTimeout      time.Duration
+
+	mu       sync.Mutex
+	circuits map[string]*hostCircuit
+	now      func() time.Time
+}
+
+func NewHostCircuitBreakers(cfg config.HttpCircuitBreaker) *HostCircuitBreakers {
+	return &HostCircuitBreakers{
+		FailureThreshold: cfg.FailureThreshold,
+		OpenTimeout:      cfg.OpenTimeout,
+		circuits:         make(map[string]*hostCircuit),
+		now:              time.Now,
+	}
+}
+
+func (b *HostCircuitBreakers) circuit(host string) *hostCircuit {
+	c, ok := b.circuits[host]
+	if !ok {
+		c = &hostCircuit{}
+		b.circuits[host] = c
+	}
+	return c
+}
+
+func (b *HostCircuitBreakers) Allow(host string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuit(host)
+	switch c.state {
+	case circuitOpen:
+		if b.now().Sub(c.openedAt) < b.OpenTimeout {
+			return &CircuitOpenError{Host: host}
+		}
+		// Let a single trial request through.
+		c.state = circuitHalfOpen
+		return nil
+	case circuitHalfOpen:
+		return &CircuitOpenError{Host: host}
+	default:
+		return nil
+	}
+}
+
+func (b *HostCircuitBreakers) Record(ctx context.Context, host string, res *http.Response, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuit(host)
+	if isContextError(err) && ctx.Err() != nil {
+		// An abandoned trial request lets the next request try again.
+		if c.state == circuitHalfOpen {
+			c.state = circuitOpen
+		}
+		return
+	}
+	if err == nil && res.StatusCode < http.StatusInternalServerError {
+		c.state = circuitClosed
+		c.failures = 0
+		return
+	}
+
+	c.failures++
+	if c.state == circuitHalfOpen || c.failures >= b.FailureThreshold {
+		c.state = circuitOpen
+		c.openedAt = b.now()
+	}
+}
@@ -0,0 +1,98 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// StatusError is returned by the typed helpers when the response status is not 2xx.
+type StatusError struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("httpclient: unexpected status %d", e.StatusCode)
+}
+
+// Decode unmarshals the JSON error body into v.
+func (e *StatusError) Decode(v interface{}) error {
+	return json.Unmarshal(e.Body, v)
+}
+
+// ErrorBody decodes the body of the *StatusError wrapped by err into E.
+// It reports false when err carries no *StatusError or the body is not a valid E.
+func ErrorBody[E any](err error) (E, bool) {
+	var body E
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		return body, false
+	}
+
+	if err := statusErr.Decode(&body); err != nil {
+		return body, false
+	}
+	return body, true
+}
+
+// DecodeJSON unmarshals the JSON body of res into v.
+func (res *Response) DecodeJSON(v interface{}) error {
+	return json.Unmarshal(res.Body, v)
+}
+
+// DecodeJSON decodes a 2xx response into T, any other status into a *StatusError.
+func DecodeJSON[T any](res *Response) (T, error) {
+	var result T
+	if !res.IsSuccess() {
+		return result, &StatusError{
+			StatusCode: res.StatusCode,
+			Header:     res.Header,
+			Body:       res.Body,
+		}
+	}
+
+	if err := res.DecodeJSON(&result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// DoJSON sends req through client and decodes the response with DecodeJSON.
+func DoJSON[T any](ctx context.Context, client IClient, req *Request) (T, error) {
+	res, err := client.Do(ctx, req)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return DecodeJSON[T](res)
+}
+
+// StreamJSON sends req through client and decodes a 2xx body into T as it is read,
+// without buffering it first. Any other status is returned as a *StatusError.
+func StreamJSON[T any](ctx context.Context, client IClient, req *Request) (T, error) {
+	var result T
+
+	res, err := client.Stream(ctx, req)
+	if err != nil {
+		return result, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		body, err := readLimited(res.Body, maxErrorBodySize)
+		if err != nil {
+			return result, err
+		}
+		return result, &StatusError{StatusCode: res.StatusCode, Header: res.Header, Body: body}
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
@@ -0,0 +1,117 @@
+package httpclient_test
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/nutsp/golang-clean-architecture/config"
+	httpClient "github.com/nutsp/golang-clean-architecture/pkg/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type availability struct {
+	Available bool `json:"available"`
+}
+
+type apiError struct {
+	Code string `json:"code"`
+}
+
+func TestDoJSONAndStreamJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"code":"taken"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"available":true}`))
+	}))
+	defer server.Close()
+
+	client := httpClient.NewClient(config.HttpClient{})
+	ctx := context.Background()
+
+	result, err := httpClient.DoJSON[availability](ctx, client, &httpClient.Request{Method: httpClient.MethodGet, URL: server.URL})
+	require.NoError(t, err)
+	assert.True(t, result.Available)
+
+	result, err = httpClient.StreamJSON[availability](ctx, client, &httpClient.Request{Method: httpClient.MethodGet, URL: server.URL})
+	require.NoError(t, err)
+	assert.True(t, result.Available)
+
+	for _, do := range []func(context.Context, httpClient.IClient, *httpClient.Request) (availability, error){
+		httpClient.DoJSON[availability],
+		httpClient.StreamJSON[availability],
+	} {
+		_, err = do(ctx, client, &httpClient.Request{Method: httpClient.MethodGet, URL: server.URL + "/fail"})
+		var statusErr *httpClient.StatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusConflict, statusErr.StatusCode)
+
+		body, ok := httpClient.ErrorBody[apiError](err)
+		assert.True(t, ok)
+		assert.Equal(t, "taken", body.Code)
+	}
+}
+
+func TestRequestBodyEncoding(t *testing.T) {
+	var contentType string
+	var form url.Values
+	var raw []byte
+	var files map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		mediaType, params, _ := mime.ParseMediaType(contentType)
+		switch mediaType {
+		case "application/x-www-form-urlencoded":
+			_ = r.ParseForm()
+			form = r.PostForm
+		case "multipart/form-data":
+			files = map[string]string{}
+			reader := multipart.NewReader(r.Body, params["boundary"])
+			for {
+				part, err := reader.NextPart()
+				if err != nil {
+					break
+				}
+				b, _ := io.ReadAll(part)
+				files[part.FormName()+":"+part.FileName()] = string(b)
+			}
+		default:
+			raw, _ = io.ReadAll(r.Body)
+		}
+	}))
+	defer server.Close()
+
+	client := httpClient.NewClient(config.HttpClient{})
+	ctx := context.Background()
+	post := func(body interface{}) {
+		_, err := client.Do(ctx, &httpClient.Request{Method: httpClient.MethodPost, URL: server.URL, Body: body})
+		require.NoError(t, err)
+	}
+
+	post(httpClient.FormBody{"email": {"john@example.com"}})
+	assert.Equal(t, "john@example.com", form.Get("email"))
+
+	post([]byte("raw"))
+	assert.Equal(t, "application/octet-stream", contentType)
+	assert.Equal(t, "raw", string(raw))
+
+	post(httpClient.RawBody{ContentType: "text/plain", Data: []byte("hello")})
+	assert.Equal(t, "text/plain", contentType)
+	assert.Equal(t, "hello", string(raw))
+
+	post(httpClient.MultipartBody{
+		Fields: map[string]string{"name": "avatar"},
+		Files:  []httpClient.MultipartFile{{FieldName: "file", FileName: "a.png", Content: []byte("png")}},
+	})
+	assert.Equal(t, "avatar", files["name:"])
+	assert.Equal(t, "png", files["file:a.png"])
+}
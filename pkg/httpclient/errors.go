@@ -0,0 +1,47 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrCircuitOpen is matched by errors.Is when a request was rejected by an open circuit breaker.
+	ErrCircuitOpen = errors.New("httpclient: circuit breaker is open")
+)
+
+// CircuitOpenError is returned when the circuit breaker of Host rejects a request.
+type CircuitOpenError struct {
+	Host string
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("httpclient: circuit breaker for %s is open", e.Host)
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// RetryError is returned when every attempt of a retried request failed.
+type RetryError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("httpclient: giving up after %d attempts: %v", e.Attempts, e.Err)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+func isCircuitOpen(err error) bool {
+	return errors.Is(err, ErrCircuitOpen)
+}
@@ -3,9 +3,11 @@ package httpclient
 import (
 	"bytes"
 	"context"
-	"encoding/json"
-	"io/ioutil"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/nutsp/golang-clean-architecture/config"
 )
@@ -19,22 +21,27 @@ var (
 )
 
 var (
-	ContentType     string = "ContentType"
+	ContentType     string = "Content-Type"
+	Accept          string = "Accept"
 	ApplicationJson string = "application/json"
 )
 
 type IClient interface {
 	Do(ctx context.Context, req *Request) (*Response, error)
+	Stream(ctx context.Context, req *Request) (*http.Response, error)
 }
 
+// maxErrorBodySize bounds how much of a body is read when it is only drained or kept for an error.
+const maxErrorBodySize = 1 << 20
+
 type Header map[string]string
 
 type Request struct {
-	Method    string
-	URL       string
-	Header    Header
-	Body      interface{}
-	bodyBytes []byte
+	Method string
+	URL    string     // Absolute URL, or a path resolved against the client base URL.
+	Query  url.Values // Encoded and merged into the query string of URL.
+	Header Header
+	Body   interface{} // A BodyEncoder, raw []byte, or any value sent as JSON.
 }
 
 type Response struct {
@@ -43,25 +50,200 @@ type Response struct {
 }
 
 type Client struct {
-	client *http.Client
+	client       *http.Client
+	baseURL      *url.URL
+	header       Header
+	retry        RetryPolicy
+	breakers     CircuitBreaker
+	limiter      RateLimiter
+	transport    http.RoundTripper
+	interceptors []Interceptor
+}
+
+// Option customizes a Client after it was built from configuration.
+type Option func(*Client)
+
+// WithRetryPolicy replaces the retry policy, nil disables retries.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// WithCircuitBreaker replaces the circuit breaker, nil disables it.
+func WithCircuitBreaker(breaker CircuitBreaker) Option {
+	return func(c *Client) {
+		c.breakers = breaker
+	}
+}
+
+// WithRateLimiter replaces the rate limiter, nil disables it.
+func WithRateLimiter(limiter RateLimiter) Option {
+	return func(c *Client) {
+		c.limiter = limiter
+	}
+}
+
+// NewClient creates a client with the default settings of cfg and no base URL.
+func NewClient(cfg config.HttpClient, opts ...Option) *Client {
+	c := newClient(cfg)
+	c.apply(opts)
+
+	return c
+}
+
+func newClient(cfg config.HttpClient) *Client {
+	c := &Client{
+		client: &http.Client{Timeout: cfg.Timeout},
+		header: mergeHeader(nil, cfg.Headers),
+	}
+	c.applyResilience(cfg.Retry, cfg.CircuitBreaker, cfg.RateLimit)
+
+	return c
+}
+
+func (c *Client) apply(opts []Option) {
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.client.Transport = Chain(c.transport, c.interceptors...)
+}
+
+// NewServiceClient creates a client for the service registered under name in cfg.Services.
+// The service settings are applied over the defaults of cfg.
+func NewServiceClient(cfg config.HttpClient, name string, opts ...Option) (*Client, error) {
+	svc, ok := cfg.Services[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("httpclient: service %q is not configured", name)
+	}
+
+	c := newClient(cfg)
+	if svc.Timeout > 0 {
+		c.client.Timeout = svc.Timeout
+	}
+	c.header = mergeHeader(c.header, svc.Headers)
+
+	retry, breaker, limit := cfg.Retry, cfg.CircuitBreaker, cfg.RateLimit
+	if svc.Retry != nil {
+		retry = *svc.Retry
+	}
+	if svc.CircuitBreaker != nil {
+		breaker = *svc.CircuitBreaker
+	}
+	if svc.RateLimit != nil {
+		limit = *svc.RateLimit
+	}
+	c.applyResilience(retry, breaker, limit)
+
+	if svc.BaseURL != "" {
+		baseURL, err := url.Parse(svc.BaseURL)
+		if err != nil {
+			return nil, fmt.Errorf("httpclient: invalid base url for service %q: %w", name, err)
+		}
+		if baseURL.Scheme == "" || baseURL.Host == "" {
+			return nil, fmt.Errorf("httpclient: base url for service %q must be absolute", name)
+		}
+		c.baseURL = baseURL
+	}
+
+	auth, err := AuthFromConfig(svc.Auth)
+	if err != nil {
+		return nil, err
+	}
+	if auth != nil {
+		c.interceptors = append(c.interceptors, auth)
+	}
+
+	c.apply(opts)
+
+	return c, nil
+}
+
+func (c *Client) applyResilience(retry config.HttpRetry, breaker config.HttpCircuitBreaker, limit config.HttpRateLimit) {
+	c.retry = nil
+	if retry.MaxAttempts > 1 {
+		c.retry = NewExponentialBackoff(retry)
+	}
+
+	c.breakers = nil
+	if breaker.Enable {
+		c.breakers = NewHostCircuitBreakers(breaker)
+	}
+
+	c.limiter = NewTokenBucketLimiter(limit)
+}
+
+func mergeHeader(dst Header, src map[string]config.Secret) Header {
+	merged := make(Header, len(dst)+len(src))
+	for k, v := range dst {
+		merged[http.CanonicalHeaderKey(k)] = v
+	}
+	for k, v := range src {
+		merged[http.CanonicalHeaderKey(k)] = v.Value()
+	}
+	return merged
 }
 
-func NewClient(cfg config.HttpClient) *Client {
-	return &Client{
-		client: &http.Client{},
+func (c *Client) resolveURL(req *Request) (string, error) {
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return "", err
+	}
+
+	if c.baseURL != nil && !u.IsAbs() {
+		base := *c.baseURL
+		base.Path = strings.TrimSuffix(base.Path, "/") + "/" + strings.TrimPrefix(u.Path, "/")
+		base.RawQuery = u.RawQuery
+		u = &base
 	}
+
+	if !u.IsAbs() {
+		return "", fmt.Errorf("httpclient: request url %q is not absolute and the client has no base url", req.URL)
+	}
+
+	if len(req.Query) > 0 {
+		q := u.Query()
+		for k, values := range req.Query {
+			for _, v := range values {
+				q.Add(k, v)
+			}
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	return u.String(), nil
 }
 
-func (req *Request) newRequest() (*http.Request, error) {
+func (c *Client) newRequest(ctx context.Context, req *Request) (*http.Request, error) {
+	var contentType string
+	var bodyBytes []byte
 	if req.Body != nil {
-		req.bodyBytes, _ = json.Marshal(req.Body)
+		var err error
+		contentType, bodyBytes, err = encodeBody(req.Body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rawURL, err := c.resolveURL(req)
+	if err != nil {
+		return nil, err
 	}
 
-	httpReq, err := http.NewRequest(req.Method, req.URL, bytes.NewReader(req.bodyBytes))
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, rawURL, bytes.NewReader(bodyBytes))
 	if err != nil {
 		return nil, err
 	}
 
+	for k, v := range c.header {
+		httpReq.Header.Set(k, v)
+	}
+
+	if contentType != "" {
+		httpReq.Header.Set(ContentType, contentType)
+	}
+
 	for k, v := range req.Header {
 		httpReq.Header.Set(k, v)
 	}
@@ -69,19 +251,17 @@ func (req *Request) newRequest() (*http.Request, error) {
 	return httpReq, nil
 }
 
+// Do sends req and buffers the whole response body, retrying according to the retry policy of the client.
+// When all attempts fail with a transport error a *RetryError is returned,
+// when they fail with a retryable status the last response is returned.
 func (c *Client) Do(ctx context.Context, req *Request) (*Response, error) {
-	httpReq, err := req.newRequest()
-	if err != nil {
-		return nil, err
-	}
-
-	res, err := c.client.Do(httpReq)
+	res, err := c.Stream(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 	defer res.Body.Close()
 
-	body, err := ioutil.ReadAll(res.Body)
+	body, err := io.ReadAll(res.Body)
 	if err != nil {
 		return nil, err
 	}
@@ -92,6 +272,66 @@ func (c *Client) Do(ctx context.Context, req *Request) (*Response, error) {
 	}, nil
 }
 
+// Stream sends req like Do but returns the response with its body unread.
+// The caller must close the body.
+func (c *Client) Stream(ctx context.Context, req *Request) (*http.Response, error) {
+	maxAttempts := 1
+	if c.retry != nil {
+		maxAttempts = c.retry.MaxAttempts(req.Method)
+	}
+
+	for attempt := 1; ; attempt++ {
+		res, err := c.send(ctx, req)
+		if attempt >= maxAttempts || !c.retry.ShouldRetry(res, err) {
+			if err != nil && attempt > 1 {
+				return nil, &RetryError{Attempts: attempt, Err: err}
+			}
+			return res, err
+		}
+
+		if res != nil {
+			// Drain the body so the connection can be reused by the next attempt.
+			_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, maxErrorBodySize))
+			res.Body.Close()
+		}
+
+		if err := sleep(ctx, c.retry.Backoff(attempt)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, req *Request) (*http.Response, error) {
+	httpReq, err := c.newRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.limiter != nil {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	host := httpReq.URL.Host
+	if c.breakers != nil {
+		if err := c.breakers.Allow(host); err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := c.client.Do(httpReq)
+	if c.breakers != nil {
+		c.breakers.Record(ctx, host, res, err)
+	}
+
+	return res, err
+}
+
 func (res *Response) IsSuccess() bool {
 	return res.StatusCode >= 200 && res.StatusCode < 300
 }
+
+func readLimited(r io.Reader, limit int64) ([]byte, error) {
+	return io.ReadAll(io.LimitReader(r, limit))
+}
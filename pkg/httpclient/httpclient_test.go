@@ -0,0 +1,82 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/nutsp/golang-clean-architecture/config"
+	httpClient "github.com/nutsp/golang-clean-architecture/pkg/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceClientDo(t *testing.T) {
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	cfg := config.HttpClient{
+		Headers: map[string]config.Secret{"accept": "application/json", "x-client": "default"},
+		Services: map[string]config.HttpService{
+			"mailer": {BaseURL: server.URL + "/v1", Headers: map[string]config.Secret{"x-client": "mailer"}},
+		},
+	}
+	client, err := httpClient.NewServiceClient(cfg, "mailer")
+	require.NoError(t, err)
+
+	resp, err := client.Do(context.Background(), &httpClient.Request{
+		Method: httpClient.MethodPost,
+		URL:    "/email-availability",
+		Query:  url.Values{"email": {"john+doe@example.com"}},
+		Body:   map[string]string{"hello": "world"},
+	})
+	require.NoError(t, err)
+	assert.True(t, resp.IsSuccess())
+	assert.Equal(t, `{"ok":true}`, string(resp.Body))
+
+	assert.Equal(t, "/v1/email-availability", got.URL.Path)
+	assert.Equal(t, "john+doe@example.com", got.URL.Query().Get("email"))
+	assert.Equal(t, "application/json", got.Header.Get("Content-Type"))
+	assert.Equal(t, "application/json", got.Header.Get("Accept"))
+	assert.Equal(t, "mailer", got.Header.Get("X-Client"))
+}
+
+func TestClientDoTimeoutAndContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client := httpClient.NewClient(config.HttpClient{Timeout: 50 * time.Millisecond})
+	_, err := client.Do(context.Background(), &httpClient.Request{Method: httpClient.MethodGet, URL: server.URL})
+	assert.Error(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = httpClient.NewClient(config.HttpClient{}).Do(ctx, &httpClient.Request{Method: httpClient.MethodGet, URL: server.URL})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestNewServiceClientErrors(t *testing.T) {
+	_, err := httpClient.NewServiceClient(config.HttpClient{}, "mailer")
+	assert.Error(t, err)
+
+	_, err = httpClient.NewServiceClient(config.HttpClient{
+		Services: map[string]config.HttpService{"mailer": {BaseURL: "api.example.com"}},
+	}, "mailer")
+	assert.Error(t, err)
+
+	_, err = httpClient.NewClient(config.HttpClient{}).Do(context.Background(), &httpClient.Request{Method: httpClient.MethodGet, URL: "/relative"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,153 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/nutsp/golang-clean-architecture/config"
+	"github.com/nutsp/golang-clean-architecture/pkg/metrics"
+	"github.com/nutsp/golang-clean-architecture/pkg/observability"
+)
+
+const (
+	HeaderAuthorization = "Authorization"
+	HeaderAPIKey        = "X-API-Key"
+	HeaderRequestID     = "X-Request-ID"
+)
+
+// RoundTripperFunc adapts a function to http.RoundTripper.
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Interceptor wraps the transport of a client. It runs once per attempt, so retries are seen individually.
+type Interceptor func(next http.RoundTripper) http.RoundTripper
+
+// Chain wraps base with interceptors, the first interceptor being the outermost.
+func Chain(base http.RoundTripper, interceptors ...Interceptor) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		base = interceptors[i](base)
+	}
+	return base
+}
+
+// WithInterceptors appends interceptors to the chain of the client.
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(c *Client) {
+		c.interceptors = append(c.interceptors, interceptors...)
+	}
+}
+
+// WithTransport replaces the transport the interceptor chain ends with.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) {
+		c.transport = transport
+	}
+}
+
+// setHeader sets a header on a copy of req, a RoundTripper must not modify the request it was given.
+func setHeader(req *http.Request, key, value string) *http.Request {
+	req = req.Clone(req.Context())
+	req.Header.Set(key, value)
+	return req
+}
+
+// BearerAuth sends token as a bearer token in the Authorization header.
+func BearerAuth(token string) Interceptor {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return next.RoundTrip(setHeader(req, HeaderAuthorization, "Bearer "+token))
+		})
+	}
+}
+
+// APIKeyAuth sends key in header, or in X-API-Key when header is empty.
+func APIKeyAuth(header, key string) Interceptor {
+	if header == "" {
+		header = HeaderAPIKey
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return next.RoundTrip(setHeader(req, header, key))
+		})
+	}
+}
+
+// AuthFromConfig returns the auth interceptor described by cfg, or nil when cfg.Type is empty.
+func AuthFromConfig(cfg config.HttpAuth) (Interceptor, error) {
+	switch cfg.Type {
+	case "":
+		return nil, nil
+	case "bearer":
+		return BearerAuth(cfg.Token.Value()), nil
+	case "apikey":
+		return APIKeyAuth(cfg.Header, cfg.Token.Value()), nil
+	default:
+		return nil, fmt.Errorf("httpclient: unknown auth type %q", cfg.Type)
+	}
+}
+
+// CorrelationID forwards the request ID found in the request context.
+func CorrelationID() Interceptor {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			requestID := observability.RequestIDFromContext(req.Context())
+			if requestID == "" || req.Header.Get(HeaderRequestID) != "" {
+				return next.RoundTrip(req)
+			}
+			return next.RoundTrip(setHeader(req, HeaderRequestID, requestID))
+		})
+	}
+}
+
+// Logging logs every outbound call with its status and latency.
+func Logging(logger observability.Logger) Interceptor {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			startTime := time.Now()
+			res, err := next.RoundTrip(req)
+
+			fields := []interface{}{
+				"method", req.Method,
+				"host", req.URL.Host,
+				"path", req.URL.Path,
+				"duration", time.Since(startTime),
+			}
+
+			logger := logger.WithContext(req.Context())
+			if err != nil {
+				logger.Error("Outbound request failed", append(fields, "error", err)...)
+				return nil, err
+			}
+
+			logger.Info("Outbound request", append(fields, "status", res.StatusCode)...)
+			return res, nil
+		})
+	}
+}
+
+// Metrics records the count and latency of every outbound call, per host.
+func Metrics(m *metrics.ClientMetrics) Interceptor {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			startTime := time.Now()
+			res, err := next.RoundTrip(req)
+
+			status := 0
+			if err == nil {
+				status = res.StatusCode
+			}
+			m.Observe(req.URL.Host, req.Method, status, time.Since(startTime))
+
+			return res, err
+		})
+	}
+}
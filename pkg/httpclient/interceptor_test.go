@@ -0,0 +1,68 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nutsp/golang-clean-architecture/config"
+	httpClient "github.com/nutsp/golang-clean-architecture/pkg/httpclient"
+	"github.com/nutsp/golang-clean-architecture/pkg/observability"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterceptorChainOrder(t *testing.T) {
+	var order []string
+	trace := func(name string) httpClient.Interceptor {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return httpClient.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				return next.RoundTrip(req)
+			})
+		}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := httpClient.NewClient(config.HttpClient{}, httpClient.WithInterceptors(trace("first"), trace("second")))
+	_, err := client.Do(context.Background(), &httpClient.Request{Method: httpClient.MethodGet, URL: server.URL})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "second"}, order)
+}
+
+func TestServiceClientAuthAndCorrelationID(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	cfg := config.HttpClient{
+		Services: map[string]config.HttpService{
+			"mailer": {BaseURL: server.URL, Auth: config.HttpAuth{Type: "bearer", Token: "secret"}},
+			"search": {BaseURL: server.URL, Auth: config.HttpAuth{Type: "apikey", Token: "key", Header: "X-Search-Key"}},
+		},
+	}
+
+	ctx := observability.ContextWithRequestID(context.Background(), "req-1")
+
+	mailer, err := httpClient.NewServiceClient(cfg, "mailer", httpClient.WithInterceptors(httpClient.CorrelationID()))
+	require.NoError(t, err)
+	_, err = mailer.Do(ctx, &httpClient.Request{Method: httpClient.MethodGet, URL: "/"})
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer secret", got.Get("Authorization"))
+	assert.Equal(t, "req-1", got.Get("X-Request-ID"))
+
+	search, err := httpClient.NewServiceClient(cfg, "search")
+	require.NoError(t, err)
+	_, err = search.Do(ctx, &httpClient.Request{Method: httpClient.MethodGet, URL: "/"})
+	require.NoError(t, err)
+	assert.Equal(t, "key", got.Get("X-Search-Key"))
+	assert.Empty(t, got.Get("X-Request-ID"))
+
+	_, err = httpClient.AuthFromConfig(config.HttpAuth{Type: "basic"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,28 @@
+package httpclient
+
+import (
+	"context"
+
+	"github.com/nutsp/golang-clean-architecture/config"
+	"golang.org/x/time/rate"
+)
+
+// RateLimiter throttles outbound requests of a client.
+type RateLimiter interface {
+	// Wait blocks until a request may be sent or ctx is done.
+	Wait(ctx context.Context) error
+}
+
+// NewTokenBucketLimiter returns a token bucket limiter, or nil when cfg disables rate limiting.
+func NewTokenBucketLimiter(cfg config.HttpRateLimit) RateLimiter {
+	if cfg.RequestsPerSecond <= 0 {
+		return nil
+	}
+
+	burst := cfg.Burst
+	if burst < 1 {
+		burst = 1
+	}
+
+	return rate.NewLimiter(rate.Limit(cfg.RequestsPerSecond), burst)
+}
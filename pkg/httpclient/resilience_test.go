@@ -0,0 +1,133 @@
+package httpclient_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nutsp/golang-clean-architecture/config"
+	httpClient "github.com/nutsp/golang-clean-architecture/pkg/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientRetriesIdempotentRequests(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := httpClient.NewClient(config.HttpClient{
+		Retry: config.HttpRetry{MaxAttempts: 3, InitialDelay: time.Millisecond, StatusCodes: []int{http.StatusServiceUnavailable}},
+	})
+
+	resp, err := client.Do(context.Background(), &httpClient.Request{Method: httpClient.MethodGet, URL: server.URL})
+	require.NoError(t, err)
+	assert.True(t, resp.IsSuccess())
+	assert.EqualValues(t, 3, atomic.LoadInt32(&calls))
+
+	atomic.StoreInt32(&calls, 0)
+	resp, err = client.Do(context.Background(), &httpClient.Request{Method: httpClient.MethodPost, URL: server.URL})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls), "POST must not be retried")
+}
+
+func TestClientRetryErrorOnTransportFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	client := httpClient.NewClient(config.HttpClient{
+		Retry: config.HttpRetry{MaxAttempts: 2, InitialDelay: time.Millisecond},
+	})
+
+	_, err := client.Do(context.Background(), &httpClient.Request{Method: httpClient.MethodGet, URL: server.URL})
+	var retryErr *httpClient.RetryError
+	require.True(t, errors.As(err, &retryErr))
+	assert.Equal(t, 2, retryErr.Attempts)
+}
+
+func TestClientCircuitBreakerOpens(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := httpClient.NewClient(config.HttpClient{
+		CircuitBreaker: config.HttpCircuitBreaker{Enable: true, FailureThreshold: 2, OpenTimeout: time.Minute},
+	})
+	req := &httpClient.Request{Method: httpClient.MethodGet, URL: server.URL}
+
+	for i := 0; i < 2; i++ {
+		_, err := client.Do(context.Background(), req)
+		require.NoError(t, err)
+	}
+
+	_, err := client.Do(context.Background(), req)
+	assert.ErrorIs(t, err, httpClient.ErrCircuitOpen)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+}
+
+func TestClientCircuitBreakerIgnoresCanceledRequests(t *testing.T) {
+	var slow int32 = 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&slow) == 1 {
+			<-r.Context().Done()
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := httpClient.NewClient(config.HttpClient{
+		CircuitBreaker: config.HttpCircuitBreaker{Enable: true, FailureThreshold: 1, OpenTimeout: time.Minute},
+	})
+	req := &httpClient.Request{Method: httpClient.MethodGet, URL: server.URL}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := client.Do(ctx, req)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The caller gave up, the host did not fail.
+	atomic.StoreInt32(&slow, 0)
+	_, err = client.Do(context.Background(), req)
+	assert.NoError(t, err)
+}
+
+func TestHostCircuitBreakersCanceledTrial(t *testing.T) {
+	breakers := httpClient.NewHostCircuitBreakers(config.HttpCircuitBreaker{FailureThreshold: 1})
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	breakers.Record(context.Background(), "api.example.com", nil, errors.New("connection refused"))
+	require.NoError(t, breakers.Allow("api.example.com"), "the open timeout elapsed, a trial is let through")
+
+	assert.ErrorIs(t, breakers.Allow("api.example.com"), httpClient.ErrCircuitOpen, "a single trial at a time")
+
+	// The trial was abandoned by its caller, the next request is the new trial.
+	breakers.Record(canceled, "api.example.com", nil, context.Canceled)
+	assert.NoError(t, breakers.Allow("api.example.com"))
+	assert.ErrorIs(t, breakers.Allow("api.example.com"), httpClient.ErrCircuitOpen)
+}
+
+func TestExponentialBackoffIsBounded(t *testing.T) {
+	policy := httpClient.NewExponentialBackoff(config.HttpRetry{InitialDelay: 10 * time.Millisecond, MaxDelay: 40 * time.Millisecond})
+
+	for retry := 1; retry < 10; retry++ {
+		delay := policy.Backoff(retry)
+		assert.GreaterOrEqual(t, delay, time.Duration(0))
+		assert.LessOrEqual(t, delay, 40*time.Millisecond)
+	}
+}
@@ -0,0 +1,96 @@
+package httpclient
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/nutsp/golang-clean-architecture/config"
+)
+
+// RetryPolicy decides whether a failed attempt is retried and how long to wait before the next one.
+type RetryPolicy interface {
+	// MaxAttempts returns how many attempts are allowed for method, including the first one.
+	MaxAttempts(method string) int
+	// ShouldRetry reports whether an attempt that ended with res or err is worth retrying.
+	ShouldRetry(res *http.Response, err error) bool
+	// Backoff returns the delay before the given retry, starting at 1.
+	Backoff(retry int) time.Duration
+}
+
+// ExponentialBackoff retries idempotent requests with full-jitter exponential backoff.
+type ExponentialBackoff struct {
+	Attempts     int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	StatusCodes  []int
+}
+
+func NewExponentialBackoff(cfg config.HttpRetry) *ExponentialBackoff {
+	return &ExponentialBackoff{
+		Attempts:     cfg.MaxAttempts,
+		InitialDelay: cfg.InitialDelay,
+		MaxDelay:     cfg.MaxDelay,
+		StatusCodes:  cfg.StatusCodes,
+	}
+}
+
+func (p *ExponentialBackoff) MaxAttempts(method string) int {
+	if p.Attempts < 1 || !isIdempotent(method) {
+		return 1
+	}
+	return p.Attempts
+}
+
+func (p *ExponentialBackoff) ShouldRetry(res *http.Response, err error) bool {
+	if err != nil {
+		// The caller gave up, or the breaker rejected the call: another attempt cannot help.
+		return !isContextError(err) && !isCircuitOpen(err)
+	}
+
+	for _, code := range p.StatusCodes {
+		if res.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *ExponentialBackoff) Backoff(retry int) time.Duration {
+	if p.InitialDelay <= 0 {
+		return 0
+	}
+
+	delay := p.InitialDelay << uint(retry-1)
+	if delay <= 0 || (p.MaxDelay > 0 && delay > p.MaxDelay) {
+		delay = p.MaxDelay
+	}
+
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
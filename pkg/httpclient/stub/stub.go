@@ -0,0 +1,248 @@
+// Package stub provides an in-process HTTP server for testing code that calls external APIs
+// through httpclient.Client end to end.
+package stub
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Fault is a failure injected instead of a normal response.
+type Fault int
+
+const (
+	// NoFault sends the stubbed response.
+	NoFault Fault = iota
+	// ConnectionReset closes the connection without writing a response.
+	ConnectionReset
+	// MalformedResponse writes bytes that are not a valid HTTP response.
+	MalformedResponse
+)
+
+// Response describes what a stubbed route answers.
+type Response struct {
+	Status int         // http.StatusOK when zero.
+	Header http.Header // Sent as is.
+	Body   []byte      // Sent as is when JSON is nil.
+	JSON   interface{} // Marshaled and sent with an application/json content type.
+}
+
+// Route is a declarative stub. Routes are matched in the order they were added.
+type Route struct {
+	Method   string     // Matches any method when empty.
+	Path     string     // Matches the request path exactly.
+	Query    url.Values // Every listed value must be present in the request query.
+	Response Response
+	Latency  time.Duration // Delay before responding, cut short when the client gives up.
+	Fault    Fault
+	Times    int // How many requests the route answers, unlimited when zero.
+
+	used int
+}
+
+// Call is a request received by the server.
+type Call struct {
+	Method  string
+	Path    string
+	Query   url.Values
+	Header  http.Header
+	Body    []byte
+	Matched bool
+}
+
+// Server is an httptest.Server answering from its routes and recording every call.
+type Server struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	routes []*Route
+	calls  []Call
+}
+
+// NewServer starts a server that is closed when the test ends.
+func NewServer(t testing.TB, routes ...Route) *Server {
+	s := &Server{}
+	for _, route := range routes {
+		s.Stub(route)
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// Stub adds a route after the existing ones.
+func (s *Server) Stub(route Route) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.routes = append(s.routes, &route)
+	return s
+}
+
+// Reset removes every route and recorded call.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.routes = nil
+	s.calls = nil
+}
+
+// Calls returns every recorded call in arrival order.
+func (s *Server) Calls() []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Call(nil), s.calls...)
+}
+
+// CallsTo returns the recorded calls for method and path.
+func (s *Server) CallsTo(method, path string) []Call {
+	var calls []Call
+	for _, call := range s.Calls() {
+		if call.Method == method && call.Path == path {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+func (s *Server) match(r *http.Request, body []byte) *Route {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	call := Call{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.Query(),
+		Header: r.Header.Clone(),
+		Body:   body,
+	}
+
+	for _, route := range s.routes {
+		if !route.matches(r) {
+			continue
+		}
+
+		route.used++
+		call.Matched = true
+		s.calls = append(s.calls, call)
+
+		matched := *route
+		return &matched
+	}
+
+	s.calls = append(s.calls, call)
+	return nil
+}
+
+func (route *Route) matches(r *http.Request) bool {
+	if route.Times > 0 && route.used >= route.Times {
+		return false
+	}
+	if route.Method != "" && route.Method != r.Method {
+		return false
+	}
+	if route.Path != r.URL.Path {
+		return false
+	}
+
+	query := r.URL.Query()
+	for key, values := range route.Query {
+		for _, v := range values {
+			if !contains(query[key], v) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	route := s.match(r, body)
+	if route == nil {
+		http.Error(w, fmt.Sprintf("stub: no route for %s %s", r.Method, r.URL.Path), http.StatusNotImplemented)
+		return
+	}
+
+	if route.Latency > 0 {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(route.Latency):
+		}
+	}
+
+	switch route.Fault {
+	case ConnectionReset:
+		hijack(w, nil)
+		return
+	case MalformedResponse:
+		hijack(w, []byte("this is not http\r\n\r\n"))
+		return
+	}
+
+	route.Response.write(w)
+}
+
+func hijack(w http.ResponseWriter, data []byte) {
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		panic("stub: response writer does not support hijacking")
+	}
+
+	conn, _, err := hj.Hijack()
+	if err != nil {
+		panic(err)
+	}
+	defer conn.Close()
+
+	if data != nil {
+		_, _ = conn.Write(data)
+	}
+}
+
+func (res Response) write(w http.ResponseWriter) {
+	body := res.Body
+	if res.JSON != nil {
+		var err error
+		if body, err = json.Marshal(res.JSON); err != nil {
+			panic(err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+	}
+
+	for key, values := range res.Header {
+		for _, v := range values {
+			w.Header().Add(key, v)
+		}
+	}
+
+	status := res.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
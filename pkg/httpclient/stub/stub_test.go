@@ -0,0 +1,53 @@
+package stub_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/nutsp/golang-clean-architecture/config"
+	httpClient "github.com/nutsp/golang-clean-architecture/pkg/httpclient"
+	"github.com/nutsp/golang-clean-architecture/pkg/httpclient/stub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerRoutesInOrder(t *testing.T) {
+	server := stub.NewServer(t,
+		stub.Route{Method: http.MethodGet, Path: "/items", Times: 1, Response: stub.Response{Status: http.StatusServiceUnavailable}},
+		stub.Route{Method: http.MethodGet, Path: "/items", Query: url.Values{"page": {"2"}}, Response: stub.Response{JSON: map[string]int{"page": 2}}},
+	)
+
+	client := httpClient.NewClient(config.HttpClient{})
+	ctx := context.Background()
+	get := func(query url.Values) *httpClient.Response {
+		res, err := client.Do(ctx, &httpClient.Request{Method: httpClient.MethodGet, URL: server.URL + "/items", Query: query})
+		require.NoError(t, err)
+		return res
+	}
+
+	assert.Equal(t, http.StatusServiceUnavailable, get(nil).StatusCode)
+	assert.Equal(t, `{"page":2}`, string(get(url.Values{"page": {"2"}}).Body))
+	assert.Equal(t, http.StatusNotImplemented, get(nil).StatusCode)
+
+	calls := server.CallsTo(http.MethodGet, "/items")
+	require.Len(t, calls, 3)
+	assert.True(t, calls[0].Matched)
+	assert.False(t, calls[2].Matched)
+}
+
+func TestServerFaults(t *testing.T) {
+	server := stub.NewServer(t,
+		stub.Route{Path: "/reset", Fault: stub.ConnectionReset},
+		stub.Route{Path: "/malformed", Fault: stub.MalformedResponse},
+		stub.Route{Path: "/slow", Latency: time.Second},
+	)
+
+	client := httpClient.NewClient(config.HttpClient{Timeout: 50 * time.Millisecond})
+	for _, path := range []string{"/reset", "/malformed", "/slow"} {
+		_, err := client.Do(context.Background(), &httpClient.Request{Method: httpClient.MethodGet, URL: server.URL + path})
+		assert.Error(t, err, path)
+	}
+}
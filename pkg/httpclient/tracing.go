@@ -0,0 +1,46 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/nutsp/golang-clean-architecture/pkg/observability"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing starts a client span per attempt and forwards the trace context to the called service.
+func Tracing() Interceptor {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			ctx, span := observability.StartSpan(req.Context(), fmt.Sprintf("HTTP %s", req.Method),
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(req.Method),
+					semconv.ServerAddress(req.URL.Hostname()),
+					semconv.URLPath(req.URL.Path),
+				),
+			)
+			defer span.End()
+
+			req = req.Clone(ctx)
+			otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+			res, err := next.RoundTrip(req)
+			if err != nil {
+				observability.RecordError(span, err)
+				return nil, err
+			}
+
+			span.SetAttributes(semconv.HTTPResponseStatusCode(res.StatusCode))
+			if res.StatusCode >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(res.StatusCode))
+			}
+
+			return res, nil
+		})
+	}
+}
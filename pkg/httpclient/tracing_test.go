@@ -0,0 +1,48 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nutsp/golang-clean-architecture/config"
+	"github.com/nutsp/golang-clean-architecture/pkg/httpclient"
+	"github.com/nutsp/golang-clean-architecture/pkg/observability"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracingInterceptor(t *testing.T) {
+	tp, err := observability.NewTracerProvider(config.AppConfig{Name: "test"}, config.ObservabilityConfig{
+		Enable: true,
+		Mode:   observability.TracingModeMemory,
+	})
+	require.NoError(t, err)
+	defer tp.Shutdown(context.Background())
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := httpclient.NewClient(config.HttpClient{}, httpclient.WithInterceptors(httpclient.Tracing()))
+
+	ctx, parent := observability.StartSpan(context.Background(), "MailerRepository.CheckEmailAvailability")
+	_, err = client.Do(ctx, &httpclient.Request{Method: httpclient.MethodGet, URL: server.URL + "/check"})
+	parent.End()
+	require.NoError(t, err)
+
+	spans := tp.Spans()
+	require.Len(t, spans, 2)
+
+	outbound := spans[0]
+	assert.Equal(t, "HTTP GET", outbound.Name)
+	assert.Equal(t, trace.SpanKindClient, outbound.SpanKind)
+	assert.Equal(t, parent.SpanContext().SpanID(), outbound.Parent.SpanID())
+	assert.Contains(t, traceparent, outbound.SpanContext.TraceID().String())
+	assert.Contains(t, traceparent, outbound.SpanContext.SpanID().String())
+}
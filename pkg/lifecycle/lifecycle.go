@@ -0,0 +1,142 @@
+// Package lifecycle starts and stops the components of the application in order.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nutsp/golang-clean-architecture/pkg/observability"
+)
+
+var (
+	// ErrStart wraps the error of the hook that failed to start.
+	ErrStart = errors.New("lifecycle: start failed")
+	// ErrStop wraps the errors of the hooks that failed to stop.
+	ErrStop = errors.New("lifecycle: stop failed")
+)
+
+// Hook starts and stops a component. Either function may be nil.
+type Hook struct {
+	Name    string
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+}
+
+// Lifecycle runs the OnStart hooks in the order they were appended, and the OnStop hooks
+// in reverse order. Components appending a hook from their constructor are therefore
+// started after their dependencies and stopped before them.
+type Lifecycle struct {
+	logger observability.Logger
+
+	mu      sync.Mutex
+	hooks   []Hook
+	started int // Number of hooks started, the ones to stop.
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func New(logger observability.Logger) *Lifecycle {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Lifecycle{
+		logger: logger,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Append adds a hook, started after the hooks already appended.
+func (l *Lifecycle) Append(hook Hook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.hooks = append(l.hooks, hook)
+}
+
+// Go runs fn in a goroutine until Stop. ctx is canceled when Stop is called,
+// and Stop waits for fn to return before running the OnStop hooks.
+func (l *Lifecycle) Go(name string, fn func(ctx context.Context)) {
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		defer func() {
+			if r := recover(); r != nil {
+				l.logger.Error("Lifecycle.Go", "goroutine", name, "panic", r)
+			}
+		}()
+
+		fn(l.ctx)
+	}()
+}
+
+// Start runs the OnStart hooks in order. When a hook fails, the hooks already started
+// are stopped and the error is returned wrapped in ErrStart.
+func (l *Lifecycle) Start(ctx context.Context) error {
+	l.mu.Lock()
+	hooks := l.hooks
+	l.mu.Unlock()
+
+	for _, hook := range hooks[l.started:] {
+		if hook.OnStart != nil {
+			startTime := time.Now()
+			if err := hook.OnStart(ctx); err != nil {
+				startErr := fmt.Errorf("%w: %s: %w", ErrStart, hook.Name, err)
+				if stopErr := l.Stop(context.WithoutCancel(ctx)); stopErr != nil {
+					return errors.Join(startErr, stopErr)
+				}
+				return startErr
+			}
+			l.logger.Info("Lifecycle.Start", "hook", hook.Name, "duration", time.Since(startTime))
+		}
+		l.started++
+	}
+
+	return nil
+}
+
+// Stop cancels the goroutines started with Go and waits for them, then runs the OnStop hooks
+// of the started hooks in reverse order. Every hook runs even when a previous one failed
+// or ctx expired, so each still gets a chance to release its resources.
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	l.cancel()
+
+	var errs []error
+
+	done := make(chan struct{})
+	go func() {
+		l.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("%w: goroutines: %w", ErrStop, ctx.Err()))
+	}
+
+	l.mu.Lock()
+	hooks := l.hooks[:l.started]
+	l.started = 0
+	l.mu.Unlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hook := hooks[i]
+		if hook.OnStop == nil {
+			continue
+		}
+
+		startTime := time.Now()
+		if err := hook.OnStop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%w: %s: %w", ErrStop, hook.Name, err))
+			l.logger.Error("Lifecycle.Stop", "hook", hook.Name, "error", err)
+			continue
+		}
+		l.logger.Info("Lifecycle.Stop", "hook", hook.Name, "duration", time.Since(startTime))
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,119 @@
+package lifecycle_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nutsp/golang-clean-architecture/config"
+	"github.com/nutsp/golang-clean-architecture/pkg/lifecycle"
+	"github.com/nutsp/golang-clean-architecture/pkg/observability"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLifecycle() *lifecycle.Lifecycle {
+	return lifecycle.New(observability.NewZapLogger(config.Logger{Level: "error"}))
+}
+
+// recordHook returns a hook appending "start:<name>" and "stop:<name>" to calls.
+func recordHook(calls *[]string, name string, startErr, stopErr error) lifecycle.Hook {
+	return lifecycle.Hook{
+		Name: name,
+		OnStart: func(context.Context) error {
+			*calls = append(*calls, "start:"+name)
+			return startErr
+		},
+		OnStop: func(context.Context) error {
+			*calls = append(*calls, "stop:"+name)
+			return stopErr
+		},
+	}
+}
+
+func TestLifecycleOrder(t *testing.T) {
+	var calls []string
+	lc := newLifecycle()
+	lc.Append(recordHook(&calls, "database", nil, nil))
+	lc.Append(recordHook(&calls, "redis", nil, nil))
+	lc.Append(lifecycle.Hook{Name: "no-op"})
+	lc.Append(recordHook(&calls, "http", nil, nil))
+
+	require.NoError(t, lc.Start(context.Background()))
+	require.NoError(t, lc.Stop(context.Background()))
+
+	assert.Equal(t, []string{
+		"start:database", "start:redis", "start:http",
+		"stop:http", "stop:redis", "stop:database",
+	}, calls)
+}
+
+func TestLifecycleStartFailure(t *testing.T) {
+	var calls []string
+	lc := newLifecycle()
+	lc.Append(recordHook(&calls, "database", nil, nil))
+	lc.Append(recordHook(&calls, "http", errors.New("address already in use"), nil))
+	lc.Append(recordHook(&calls, "never", nil, nil))
+
+	err := lc.Start(context.Background())
+
+	assert.ErrorIs(t, err, lifecycle.ErrStart)
+	assert.ErrorContains(t, err, "http: address already in use")
+	// Only the hooks that started are stopped.
+	assert.Equal(t, []string{"start:database", "start:http", "stop:database"}, calls)
+}
+
+func TestLifecycleStopFailure(t *testing.T) {
+	var calls []string
+	lc := newLifecycle()
+	lc.Append(recordHook(&calls, "database", nil, nil))
+	lc.Append(recordHook(&calls, "redis", nil, errors.New("connection reset")))
+
+	require.NoError(t, lc.Start(context.Background()))
+	err := lc.Stop(context.Background())
+
+	assert.ErrorIs(t, err, lifecycle.ErrStop)
+	assert.ErrorContains(t, err, "redis: connection reset")
+	// The failure does not prevent the next hooks from stopping.
+	assert.Equal(t, []string{"start:database", "start:redis", "stop:redis", "stop:database"}, calls)
+}
+
+func TestLifecycleGoroutines(t *testing.T) {
+	var calls []string
+	lc := newLifecycle()
+	lc.Append(recordHook(&calls, "redis", nil, nil))
+	require.NoError(t, lc.Start(context.Background()))
+
+	lc.Go("subscriber", func(ctx context.Context) {
+		<-ctx.Done()
+		calls = append(calls, "return:subscriber")
+	})
+	lc.Go("panicking", func(context.Context) {
+		panic("nil map")
+	})
+
+	require.NoError(t, lc.Stop(context.Background()))
+
+	// The goroutines return before the resources they use are closed.
+	assert.Equal(t, []string{"start:redis", "return:subscriber", "stop:redis"}, calls)
+}
+
+func TestLifecycleStopTimeout(t *testing.T) {
+	var calls []string
+	lc := newLifecycle()
+	lc.Append(recordHook(&calls, "redis", nil, nil))
+	require.NoError(t, lc.Start(context.Background()))
+
+	block := make(chan struct{})
+	defer close(block)
+	lc.Go("stuck", func(context.Context) { <-block })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := lc.Stop(ctx)
+
+	assert.ErrorIs(t, err, lifecycle.ErrStop)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, []string{"start:redis", "stop:redis"}, calls)
+}
@@ -0,0 +1,220 @@
+// Package metrics defines the Prometheus collectors of the application.
+// Every collector is safe to use through a nil pointer, so components work without metrics in tests.
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry exposes the registered collectors together with the Go runtime and process metrics.
+type Registry struct {
+	registry *prometheus.Registry
+}
+
+// NewRegistry registers cs and reports every collector that failed to register.
+func NewRegistry(cs ...prometheus.Collector) (*Registry, error) {
+	registry := prometheus.NewRegistry()
+
+	cs = append([]prometheus.Collector{
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	}, cs...)
+
+	var errs []error
+	for _, c := range cs {
+		if err := registry.Register(c); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return &Registry{registry: registry}, errors.Join(errs...)
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (r *Registry) Handler() http.Handler {
+	return promhttp.HandlerFor(r.registry, promhttp.HandlerOpts{Registry: r.registry})
+}
+
+// Gatherer returns the underlying registry, for tests.
+func (r *Registry) Gatherer() prometheus.Gatherer {
+	return r.registry
+}
+
+// HTTPMetrics holds the RED metrics of the HTTP server, per route.
+type HTTPMetrics struct {
+	requests *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+func NewHTTPMetrics() *HTTPMetrics {
+	return &HTTPMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_server_requests_total",
+			Help: "Number of HTTP requests handled, by route and status.",
+		}, []string{"method", "route", "status"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_server_errors_total",
+			Help: "Number of HTTP requests that failed, by route and error code.",
+		}, []string{"method", "route", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_server_request_duration_seconds",
+			Help:    "Latency of HTTP requests, by route.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+	}
+}
+
+// Observe records a handled request. code is the error code of a failed request, empty otherwise.
+func (m *HTTPMetrics) Observe(method, route string, status int, code string, duration time.Duration) {
+	if m == nil {
+		return
+	}
+
+	m.requests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.duration.WithLabelValues(method, route).Observe(duration.Seconds())
+	if code != "" {
+		m.errors.WithLabelValues(method, route, code).Inc()
+	}
+}
+
+func (m *HTTPMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.requests.Describe(ch)
+	m.errors.Describe(ch)
+	m.duration.Describe(ch)
+}
+
+func (m *HTTPMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.requests.Collect(ch)
+	m.errors.Collect(ch)
+	m.duration.Collect(ch)
+}
+
+// ClientMetrics holds the metrics of outbound HTTP calls, per host.
+type ClientMetrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+func NewClientMetrics() *ClientMetrics {
+	return &ClientMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_client_requests_total",
+			Help: "Number of outbound HTTP requests, by host and status. Transport errors have the status \"error\".",
+		}, []string{"host", "method", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_client_request_duration_seconds",
+			Help:    "Latency of outbound HTTP requests, by host.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"host", "method"}),
+	}
+}
+
+// Observe records an outbound request. A status of 0 means the request failed before a response.
+func (m *ClientMetrics) Observe(host, method string, status int, duration time.Duration) {
+	if m == nil {
+		return
+	}
+
+	label := "error"
+	if status > 0 {
+		label = strconv.Itoa(status)
+	}
+
+	m.requests.WithLabelValues(host, method, label).Inc()
+	m.duration.WithLabelValues(host, method).Observe(duration.Seconds())
+}
+
+func (m *ClientMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.requests.Describe(ch)
+	m.duration.Describe(ch)
+}
+
+func (m *ClientMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.requests.Collect(ch)
+	m.duration.Collect(ch)
+}
+
+// RedisMetrics holds the latency and failures of Redis commands.
+type RedisMetrics struct {
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+}
+
+func NewRedisMetrics() *RedisMetrics {
+	return &RedisMetrics{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "redis_command_duration_seconds",
+			Help:    "Latency of Redis commands, by command. Pipelines are reported as \"pipeline\".",
+			Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"command"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "redis_command_errors_total",
+			Help: "Number of failed Redis commands, by command. Missing keys are not failures.",
+		}, []string{"command"}),
+	}
+}
+
+func (m *RedisMetrics) Observe(command string, failed bool, duration time.Duration) {
+	if m == nil {
+		return
+	}
+
+	m.duration.WithLabelValues(command).Observe(duration.Seconds())
+	if failed {
+		m.errors.WithLabelValues(command).Inc()
+	}
+}
+
+func (m *RedisMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.duration.Describe(ch)
+	m.errors.Describe(ch)
+}
+
+func (m *RedisMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.duration.Collect(ch)
+	m.errors.Collect(ch)
+}
+
+// CacheMetrics counts the lookups of application caches, by cache and result.
+type CacheMetrics struct {
+	requests *prometheus.CounterVec
+}
+
+func NewCacheMetrics() *CacheMetrics {
+	return &CacheMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cache_requests_total",
+			Help: "Number of cache lookups, by cache and result (hit or miss).",
+		}, []string{"cache", "result"}),
+	}
+}
+
+func (m *CacheMetrics) Hit(cache string) {
+	if m == nil {
+		return
+	}
+	m.requests.WithLabelValues(cache, "hit").Inc()
+}
+
+func (m *CacheMetrics) Miss(cache string) {
+	if m == nil {
+		return
+	}
+	m.requests.WithLabelValues(cache, "miss").Inc()
+}
+
+func (m *CacheMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.requests.Describe(ch)
+}
+
+func (m *CacheMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.requests.Collect(ch)
+}
@@ -0,0 +1,82 @@
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nutsp/golang-clean-architecture/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryHandler(t *testing.T) {
+	httpMetrics := metrics.NewHTTPMetrics()
+	cacheMetrics := metrics.NewCacheMetrics()
+
+	registry, err := metrics.NewRegistry(httpMetrics, cacheMetrics)
+	require.NoError(t, err)
+
+	httpMetrics.Observe(http.MethodPost, "/api/v1/users", http.StatusConflict, "conflict", 20*time.Millisecond)
+	cacheMetrics.Hit("users")
+	cacheMetrics.Miss("users")
+	cacheMetrics.Miss("users")
+
+	rec := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := rec.Body.String()
+	assert.Contains(t, body, `http_server_requests_total{method="POST",route="/api/v1/users",status="409"} 1`)
+	assert.Contains(t, body, `http_server_errors_total{code="conflict",method="POST",route="/api/v1/users"} 1`)
+	assert.Contains(t, body, `http_server_request_duration_seconds_count{method="POST",route="/api/v1/users"} 1`)
+	assert.Contains(t, body, `cache_requests_total{cache="users",result="miss"} 2`)
+	assert.Contains(t, body, "go_goroutines")
+}
+
+func TestRegistryReportsDuplicateCollectors(t *testing.T) {
+	cacheMetrics := metrics.NewCacheMetrics()
+
+	_, err := metrics.NewRegistry(cacheMetrics, cacheMetrics)
+	assert.Error(t, err)
+}
+
+func TestClientAndRedisMetrics(t *testing.T) {
+	clientMetrics := metrics.NewClientMetrics()
+	clientMetrics.Observe("api.example.com", http.MethodGet, http.StatusOK, time.Millisecond)
+	clientMetrics.Observe("api.example.com", http.MethodGet, 0, time.Millisecond)
+
+	assert.NoError(t, testutil.CollectAndCompare(clientMetrics, strings.NewReader(`
+# HELP http_client_requests_total Number of outbound HTTP requests, by host and status. Transport errors have the status "error".
+# TYPE http_client_requests_total counter
+http_client_requests_total{host="api.example.com",method="GET",status="200"} 1
+http_client_requests_total{host="api.example.com",method="GET",status="error"} 1
+`), "http_client_requests_total"))
+
+	redisMetrics := metrics.NewRedisMetrics()
+	redisMetrics.Observe("get", false, time.Millisecond)
+	redisMetrics.Observe("set", true, time.Millisecond)
+
+	assert.NoError(t, testutil.CollectAndCompare(redisMetrics, strings.NewReader(`
+# HELP redis_command_errors_total Number of failed Redis commands, by command. Missing keys are not failures.
+# TYPE redis_command_errors_total counter
+redis_command_errors_total{command="set"} 1
+`), "redis_command_errors_total"))
+}
+
+func TestNilMetricsAreNoops(t *testing.T) {
+	var httpMetrics *metrics.HTTPMetrics
+	var clientMetrics *metrics.ClientMetrics
+	var redisMetrics *metrics.RedisMetrics
+	var cacheMetrics *metrics.CacheMetrics
+
+	assert.NotPanics(t, func() {
+		httpMetrics.Observe(http.MethodGet, "/", http.StatusOK, "", time.Millisecond)
+		clientMetrics.Observe("host", http.MethodGet, http.StatusOK, time.Millisecond)
+		redisMetrics.Observe("get", false, time.Millisecond)
+		cacheMetrics.Hit("users")
+		cacheMetrics.Miss("users")
+	})
+}
@@ -0,0 +1,175 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/nutsp/golang-clean-architecture/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const (
+	TracingModeOTLPHTTP string = "otlp/http"
+	TracingModeStdout   string = "stdout"
+	TracingModeMemory   string = "memory"
+)
+
+// instrumentationName identifies the spans created by this application.
+const instrumentationName = "github.com/nutsp/golang-clean-architecture"
+
+// TracerProvider owns the exporter selected by config.ObservabilityConfig.
+// It is installed as the global OpenTelemetry provider, so spans can be started anywhere with StartSpan.
+type TracerProvider struct {
+	trace.TracerProvider
+	sdk    *sdktrace.TracerProvider
+	memory *tracetest.InMemoryExporter
+}
+
+// NewTracerProvider builds the tracer provider for cfg and installs it globally together with
+// the W3C trace context propagator. When tracing is disabled spans are not recorded,
+// but incoming trace context is still propagated to outbound calls.
+func NewTracerProvider(app config.AppConfig, cfg config.ObservabilityConfig) (*TracerProvider, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if !cfg.Enable {
+		tp := &TracerProvider{TracerProvider: noop.NewTracerProvider()}
+		otel.SetTracerProvider(tp.TracerProvider)
+		return tp, nil
+	}
+
+	tp := &TracerProvider{}
+
+	var exporter sdktrace.SpanExporter
+	switch cfg.Mode {
+	case TracingModeOTLPHTTP:
+		opts := []otlptracehttp.Option{}
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		if len(cfg.Headers) > 0 {
+			headers := make(map[string]string, len(cfg.Headers))
+			for k, v := range cfg.Headers {
+				headers[k] = v.Value()
+			}
+			opts = append(opts, otlptracehttp.WithHeaders(headers))
+		}
+
+		// The exporter connects lazily, so no request is sent here.
+		otlp, err := otlptracehttp.New(context.Background(), opts...)
+		if err != nil {
+			return nil, fmt.Errorf("tracing: create otlp exporter: %w", err)
+		}
+		exporter = otlp
+	case TracingModeStdout:
+		stdout, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, fmt.Errorf("tracing: create stdout exporter: %w", err)
+		}
+		exporter = stdout
+	case TracingModeMemory:
+		tp.memory = tracetest.NewInMemoryExporter()
+		exporter = tp.memory
+	default:
+		return nil, fmt.Errorf("tracing: unknown mode %q", cfg.Mode)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(app.Name),
+		semconv.ServiceVersion(app.Version),
+		semconv.DeploymentEnvironment(app.Environment),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("tracing: build resource: %w", err)
+	}
+
+	sampler := sdktrace.AlwaysSample()
+	if cfg.SampleRatio > 0 && cfg.SampleRatio < 1 {
+		sampler = sdktrace.TraceIDRatioBased(cfg.SampleRatio)
+	}
+
+	// The in-memory exporter is read right after a request, so its spans are not batched.
+	processor := sdktrace.WithBatcher(exporter)
+	if tp.memory != nil {
+		processor = sdktrace.WithSyncer(exporter)
+	}
+
+	tp.sdk = sdktrace.NewTracerProvider(
+		processor,
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sampler)),
+	)
+	tp.TracerProvider = tp.sdk
+	otel.SetTracerProvider(tp.sdk)
+
+	return tp, nil
+}
+
+// Shutdown flushes the spans still buffered and stops the exporter.
+func (tp *TracerProvider) Shutdown(ctx context.Context) error {
+	if tp.sdk == nil {
+		return nil
+	}
+	return tp.sdk.Shutdown(ctx)
+}
+
+// Spans returns the spans recorded so far in "memory" mode, and nil in the other modes.
+func (tp *TracerProvider) Spans() tracetest.SpanStubs {
+	if tp.memory == nil {
+		return nil
+	}
+	return tp.memory.GetSpans()
+}
+
+// Reset discards the spans recorded in "memory" mode.
+func (tp *TracerProvider) Reset() {
+	if tp.memory != nil {
+		tp.memory.Reset()
+	}
+}
+
+// Tracer returns the application tracer of the global provider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// StartSpan starts a child span of the span in ctx, typically named "<Component>.<Method>".
+// When the span is not recorded, because tracing is disabled or the trace is not sampled,
+// ctx is returned as is so callers pay nothing for tracing.
+func StartSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	spanCtx, span := Tracer().Start(ctx, name, opts...)
+	if !span.IsRecording() {
+		return ctx, span
+	}
+	return spanCtx, span
+}
+
+// EndSpan records err on span, if any, and ends it.
+func EndSpan(span trace.Span, err error) {
+	RecordError(span, err)
+	span.End()
+}
+
+// RecordError marks span as failed with err. A nil err or a canceled request is not an error of the span.
+func RecordError(span trace.Span, err error) {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,55 @@
+package observability_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nutsp/golang-clean-architecture/config"
+	"github.com/nutsp/golang-clean-architecture/pkg/observability"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestTracerProviderMemoryMode(t *testing.T) {
+	tp, err := observability.NewTracerProvider(config.AppConfig{Name: "test"}, config.ObservabilityConfig{
+		Enable: true,
+		Mode:   observability.TracingModeMemory,
+	})
+	require.NoError(t, err)
+	defer tp.Shutdown(context.Background())
+
+	ctx, parent := observability.StartSpan(context.Background(), "UserUsecase.CreateUser")
+	_, child := observability.StartSpan(ctx, "UserRepository.Save")
+	observability.EndSpan(child, errors.New("duplicate entry"))
+	parent.End()
+
+	spans := tp.Spans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "UserRepository.Save", spans[0].Name)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent.SpanID())
+	assert.Equal(t, "UserUsecase.CreateUser", spans[1].Name)
+	assert.Equal(t, codes.Unset, spans[1].Status.Code)
+
+	tp.Reset()
+	assert.Empty(t, tp.Spans())
+}
+
+func TestTracerProviderDisabled(t *testing.T) {
+	tp, err := observability.NewTracerProvider(config.AppConfig{}, config.ObservabilityConfig{})
+	require.NoError(t, err)
+
+	_, span := observability.StartSpan(context.Background(), "UserUsecase.CreateUser")
+	span.End()
+
+	assert.False(t, span.IsRecording())
+	assert.Nil(t, tp.Spans())
+	assert.NoError(t, tp.Shutdown(context.Background()))
+}
+
+func TestTracerProviderUnknownMode(t *testing.T) {
+	_, err := observability.NewTracerProvider(config.AppConfig{}, config.ObservabilityConfig{Enable: true, Mode: "zipkin"})
+	assert.Error(t, err)
+}
@@ -1,11 +1,16 @@
 package observability
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"time"
 
 	"github.com/nutsp/golang-clean-architecture/config"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 const (
@@ -13,15 +18,36 @@ const (
 	Production  string = "production"
 )
 
+// Outputs of config.Logger.
+const (
+	OutputStdout string = "stdout"
+	OutputStderr string = "stderr"
+	OutputFile   string = "file"
+)
+
 type (
 	Logger interface {
 		Info(msg string, fields ...interface{})
 		Error(msg string, fields ...interface{})
 		Debug(msg string, fields ...interface{})
+		Warn(msg string, fields ...interface{})
+		// Fatal logs and exits the process with status 1.
+		Fatal(msg string, fields ...interface{})
+		// With returns a logger that adds fields to every line.
+		With(fields ...interface{}) Logger
+		// WithContext returns a logger that adds the request ID and trace IDs found in ctx to every line.
+		WithContext(ctx context.Context) Logger
+	}
+
+	// LevelController reads and changes the minimum level of a logger while it runs.
+	LevelController interface {
+		Level() string
+		SetLevel(level string) error
 	}
 
 	ZapLogger struct {
 		logger *zap.SugaredLogger
+		level  zap.AtomicLevel
 	}
 )
 
@@ -69,13 +95,61 @@ func NewZapLogger(cfg config.Logger) *ZapLogger {
 		encoder = zapcore.NewJSONEncoder(config)
 	}
 
-	core := zapcore.NewCore(encoder, zapcore.AddSync(os.Stderr), zap.NewAtomicLevelAt(zapcore.DebugLevel))
+	// The logger is built before the configuration is validated, so a mistake is
+	// reported through the logger rather than failing the boot.
+	var warnings []string
+
+	level := zap.NewAtomicLevelAt(zapcore.DebugLevel)
+	if cfg.Level != "" {
+		if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+			warnings = append(warnings, fmt.Sprintf("unknown log level %q, using debug", cfg.Level))
+		}
+	}
+
+	outputs := cfg.Outputs
+	if len(outputs) == 0 {
+		outputs = []string{OutputStderr}
+	}
+
+	var syncers []zapcore.WriteSyncer
+	for _, output := range outputs {
+		switch output {
+		case OutputStdout:
+			syncers = append(syncers, zapcore.Lock(os.Stdout))
+		case OutputStderr:
+			syncers = append(syncers, zapcore.Lock(os.Stderr))
+		case OutputFile:
+			// lumberjack rotates the file once it reaches MaxSize, and creates its directory.
+			syncers = append(syncers, zapcore.AddSync(&lumberjack.Logger{
+				Filename:   cfg.File.Path,
+				MaxSize:    cfg.File.MaxSize,
+				MaxBackups: cfg.File.MaxBackups,
+				MaxAge:     cfg.File.MaxAge,
+				Compress:   cfg.File.Compress,
+			}))
+		default:
+			warnings = append(warnings, fmt.Sprintf("unknown log output %q, ignored", output))
+		}
+	}
+	if len(syncers) == 0 {
+		syncers = append(syncers, zapcore.Lock(os.Stderr))
+	}
+
+	core := zapcore.NewCore(encoder, zapcore.NewMultiWriteSyncer(syncers...), level)
+	if cfg.Sampling.Enable {
+		core = zapcore.NewSamplerWithOptions(core, time.Second, cfg.Sampling.Initial, cfg.Sampling.Thereafter)
+	}
 	zapLogger := zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
 
-	sugaredLogger := zapLogger.Sugar()
-	return &ZapLogger{
-		logger: sugaredLogger,
+	logger := &ZapLogger{
+		logger: zapLogger.Sugar(),
+		level:  level,
+	}
+	for _, warning := range warnings {
+		logger.Warn("NewZapLogger", "warning", warning)
 	}
+
+	return logger
 }
 
 func (l *ZapLogger) Info(msg string, fields ...interface{}) {
@@ -89,3 +163,54 @@ func (l *ZapLogger) Error(msg string, fields ...interface{}) {
 func (l *ZapLogger) Debug(msg string, fields ...interface{}) {
 	l.logger.Debugw(msg, fields...)
 }
+
+func (l *ZapLogger) Warn(msg string, fields ...interface{}) {
+	l.logger.Warnw(msg, fields...)
+}
+
+func (l *ZapLogger) Fatal(msg string, fields ...interface{}) {
+	l.logger.Fatalw(msg, fields...)
+}
+
+func (l *ZapLogger) With(fields ...interface{}) Logger {
+	return &ZapLogger{
+		logger: l.logger.With(fields...),
+		level:  l.level,
+	}
+}
+
+// Level returns the current minimum level, shared by every logger derived from l.
+func (l *ZapLogger) Level() string {
+	return l.level.String()
+}
+
+// SetLevel changes the minimum level of l and of every logger derived from it.
+func (l *ZapLogger) SetLevel(level string) error {
+	parsed, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.level.SetLevel(parsed)
+	return nil
+}
+
+// Sync flushes the buffered lines.
+func (l *ZapLogger) Sync() error {
+	return l.logger.Sync()
+}
+
+func (l *ZapLogger) WithContext(ctx context.Context) Logger {
+	var fields []interface{}
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		fields = append(fields, FieldRequestID, requestID)
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		fields = append(fields, FieldTraceID, spanContext.TraceID().String(), FieldSpanID, spanContext.SpanID().String())
+	}
+
+	if len(fields) == 0 {
+		return l
+	}
+
+	return l.With(fields...)
+}
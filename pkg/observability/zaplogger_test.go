@@ -0,0 +1,93 @@
+package observability_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nutsp/golang-clean-architecture/config"
+	"github.com/nutsp/golang-clean-architecture/pkg/observability"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readLines(t *testing.T, path string) []map[string]interface{} {
+	t.Helper()
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	require.NoError(t, scanner.Err())
+	return lines
+}
+
+func TestZapLoggerLevel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+	logger := observability.NewZapLogger(config.Logger{
+		Level:   "warn",
+		Outputs: []string{observability.OutputFile},
+		File:    config.LoggerFile{Path: path},
+	})
+	assert.Equal(t, "warn", logger.Level())
+
+	derived := logger.With("component", "test")
+	derived.Info("dropped")
+	derived.Warn("kept")
+
+	// Changing the level applies to the loggers derived before the change.
+	require.NoError(t, logger.SetLevel("debug"))
+	derived.Debug("kept after change")
+	assert.Error(t, logger.SetLevel("verbose"))
+	require.NoError(t, logger.Sync())
+
+	lines := readLines(t, path)
+	require.Len(t, lines, 2)
+	assert.Equal(t, "kept", lines[0]["msg"])
+	assert.Equal(t, "warn", lines[0]["level"])
+	assert.Equal(t, "test", lines[0]["component"])
+	assert.Equal(t, "kept after change", lines[1]["msg"])
+}
+
+func TestZapLoggerInvalidConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	logger := observability.NewZapLogger(config.Logger{
+		Level:   "verbose",
+		Outputs: []string{observability.OutputFile, "syslog"},
+		File:    config.LoggerFile{Path: path},
+	})
+	require.NoError(t, logger.Sync())
+
+	// The logger falls back to debug and reports the mistakes.
+	assert.Equal(t, "debug", logger.Level())
+	lines := readLines(t, path)
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0]["warning"], `unknown log level "verbose"`)
+	assert.Contains(t, lines[1]["warning"], `unknown log output "syslog"`)
+}
+
+func TestZapLoggerSampling(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	logger := observability.NewZapLogger(config.Logger{
+		Outputs:  []string{observability.OutputFile},
+		File:     config.LoggerFile{Path: path},
+		Sampling: config.LoggerSampling{Enable: true, Initial: 2, Thereafter: 5},
+	})
+
+	for i := 0; i < 12; i++ {
+		logger.Info("repeated")
+	}
+	require.NoError(t, logger.Sync())
+
+	// 2 lines, then the 5th and the 10th of the 10 others.
+	assert.Len(t, readLines(t, path), 4)
+}
@@ -0,0 +1,100 @@
+// Package ratelimit implements a distributed sliding window rate limiter on top of Redis.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/nutsp/golang-clean-architecture/pkg/datasource"
+)
+
+// Result is the outcome of a single Allow call.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	Reset     time.Duration // Time until the current window ends.
+}
+
+type Limiter interface {
+	// Allow counts a request for key and reports whether it stays within limit requests per window.
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (Result, error)
+}
+
+// SlidingWindow approximates a sliding window with two fixed windows: the count of the previous
+// window is weighted by how much of it still overlaps the sliding window.
+// It only needs INCR, EXPIRE and GET, so it works on any Redis deployment.
+type SlidingWindow struct {
+	client datasource.IRedisClient
+	now    func() time.Time
+}
+
+func NewSlidingWindow(client datasource.IRedisClient) *SlidingWindow {
+	return &SlidingWindow{
+		client: client,
+		now:    time.Now,
+	}
+}
+
+// SetClock replaces the clock, for tests.
+func (l *SlidingWindow) SetClock(now func() time.Time) {
+	l.now = now
+}
+
+func (l *SlidingWindow) Allow(ctx context.Context, key string, limit int, window time.Duration) (Result, error) {
+	if window <= 0 {
+		return Result{}, errors.New("ratelimit: window must be positive")
+	}
+
+	now := l.now()
+	index := now.UnixNano() / int64(window)
+	elapsed := time.Duration(now.UnixNano() - index*int64(window))
+
+	currKey := l.client.GetKeyName("rate_limit", fmt.Sprintf("%s:%d", key, index))
+	prevKey := l.client.GetKeyName("rate_limit", fmt.Sprintf("%s:%d", key, index-1))
+
+	var curr datasource.IntResult
+	var prev datasource.StringResult
+	err := l.client.Pipeline(ctx, func(pipe datasource.Pipeliner) error {
+		curr = pipe.Incr(ctx, currKey)
+		// The window is still read as the previous one during the whole next window.
+		pipe.Expire(ctx, currKey, 2*window)
+		prev = pipe.Get(ctx, prevKey)
+		return nil
+	})
+	if err != nil {
+		return Result{}, err
+	}
+
+	currCount, err := curr.Result()
+	if err != nil {
+		return Result{}, err
+	}
+
+	var prevCount int64
+	if val, err := prev.Result(); err == nil {
+		prevCount, _ = strconv.ParseInt(val, 10, 64)
+	} else if !errors.Is(err, redis.Nil) {
+		return Result{}, err
+	}
+
+	weight := 1 - float64(elapsed)/float64(window)
+	count := int(math.Ceil(float64(prevCount)*weight)) + int(currCount)
+
+	remaining := limit - count
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return Result{
+		Allowed:   count <= limit,
+		Limit:     limit,
+		Remaining: remaining,
+		Reset:     window - elapsed,
+	}, nil
+}
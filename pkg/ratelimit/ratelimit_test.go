@@ -0,0 +1,57 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nutsp/golang-clean-architecture/pkg/datasource"
+	"github.com/nutsp/golang-clean-architecture/pkg/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlidingWindow(t *testing.T) {
+	ctx := context.Background()
+	client := datasource.NewMemoryRedisClient()
+	limiter := ratelimit.NewSlidingWindow(client)
+
+	now := time.Unix(0, 0).Add(time.Hour)
+	clock := func() time.Time { return now }
+	client.SetClock(clock)
+	limiter.SetClock(clock)
+
+	for i := 1; i <= 3; i++ {
+		res, err := limiter.Allow(ctx, "ip:1.2.3.4", 3, time.Minute)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 3-i, res.Remaining)
+	}
+
+	res, err := limiter.Allow(ctx, "ip:1.2.3.4", 3, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, time.Minute, res.Reset)
+
+	res, err = limiter.Allow(ctx, "ip:5.6.7.8", 3, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed, "keys are limited independently")
+
+	// Half way through the next window, half of the previous four requests still count.
+	now = now.Add(90 * time.Second)
+	res, err = limiter.Allow(ctx, "ip:1.2.3.4", 3, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+
+	res, err = limiter.Allow(ctx, "ip:1.2.3.4", 3, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+
+	// Once the previous window no longer overlaps, the budget is back.
+	now = now.Add(2 * time.Minute)
+	res, err = limiter.Allow(ctx, "ip:1.2.3.4", 3, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 2, res.Remaining)
+}